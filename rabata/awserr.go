@@ -3,10 +3,14 @@ package rabata
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 )
 
@@ -38,10 +42,55 @@ func isAWSErrRequestFailureStatusCode(err error, statusCode int) bool {
 	return false
 }
 
+// s3RegionRedirectHandler adds the region of the bucket to 301 errors. S3 answers requests
+// sent to the endpoint of another region with PermanentRedirect, or a bodiless 301 for HEAD requests,
+// and the region is only available in the x-amz-bucket-region header.
+var s3RegionRedirectHandler = request.NamedHandler{
+	Name: "rabata.s3RegionRedirectHandler",
+	Fn: func(r *request.Request) {
+		if r.Error == nil || r.HTTPResponse == nil || r.HTTPResponse.StatusCode != http.StatusMovedPermanently {
+			return
+		}
+
+		region := r.HTTPResponse.Header.Get("X-Amz-Bucket-Region")
+		if region == "" {
+			return
+		}
+
+		code := "PermanentRedirect"
+		message := r.Error.Error()
+
+		var awsErr awserr.Error
+		if errors.As(r.Error, &awsErr) {
+			code = awsErr.Code()
+			message = awsErr.Message()
+		}
+
+		// The error code is kept, so that isAWSErr still matches the error.
+		r.Error = awserr.NewRequestFailure(
+			awserr.New(code, fmt.Sprintf("%s: the bucket is in region %q, configure the provider with "+
+				"region = %q and its S3 endpoint", message, region, region), r.Error),
+			http.StatusMovedPermanently,
+			r.RequestID,
+		)
+	},
+}
+
+// s3ThrottlingErrorCodes are the error codes S3 returns when a bucket or key is under
+// too much load to serve the request right away.
+var s3ThrottlingErrorCodes = []string{"SlowDown", "ServiceUnavailable"}
+
 func retryOnAWSCode(ctx context.Context, code string, f func() (any, error)) (any, error) {
+	return retryOnAWSCodes(ctx, 2*time.Minute, []string{code}, f) //nolint:mnd
+}
+
+// retryOnAWSCodes retries f until timeout while it fails with any of codes.
+// 503 Service Unavailable responses are always retried, as S3 may return them
+// without an error code in the body.
+func retryOnAWSCodes(ctx context.Context, timeout time.Duration, codes []string, f func() (any, error)) (any, error) {
 	var resp any
 
-	err := retry.RetryContext(ctx, 2*time.Minute, func() *retry.RetryError { //nolint:mnd
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
 		var err error
 
 		resp, err = f()
@@ -49,7 +98,11 @@ func retryOnAWSCode(ctx context.Context, code string, f func() (any, error)) (an
 			var awsErr awserr.Error
 
 			ok := errors.As(err, &awsErr)
-			if ok && awsErr.Code() == code {
+			if ok && slices.Contains(codes, awsErr.Code()) {
+				return retry.RetryableError(err)
+			}
+
+			if isAWSErrRequestFailureStatusCode(err, http.StatusServiceUnavailable) {
 				return retry.RetryableError(err)
 			}
 
@@ -0,0 +1,147 @@
+package rabata
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+func TestS3RegionRedirectHandler(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err             error
+		statusCode      int
+		bucketRegion    string
+		expectedCode    string
+		expectedMessage string
+	}{
+		"permanent redirect": {
+			err:             awserr.New("PermanentRedirect", "use the specified endpoint", nil),
+			statusCode:      http.StatusMovedPermanently,
+			bucketRegion:    "us-east-1",
+			expectedCode:    "PermanentRedirect",
+			expectedMessage: `the bucket is in region "us-east-1"`,
+		},
+		"bodiless head": {
+			err:             awserr.New("MovedPermanently", "Moved Permanently", nil),
+			statusCode:      http.StatusMovedPermanently,
+			bucketRegion:    "us-east-1",
+			expectedCode:    "MovedPermanently",
+			expectedMessage: `the bucket is in region "us-east-1"`,
+		},
+		"not an aws error": {
+			err:             errors.New("redirect"),
+			statusCode:      http.StatusMovedPermanently,
+			bucketRegion:    "us-east-1",
+			expectedCode:    "PermanentRedirect",
+			expectedMessage: `region = "us-east-1"`,
+		},
+		"no bucket region": {
+			err:             awserr.New("PermanentRedirect", "use the specified endpoint", nil),
+			statusCode:      http.StatusMovedPermanently,
+			expectedCode:    "PermanentRedirect",
+			expectedMessage: "use the specified endpoint",
+		},
+		"other status code": {
+			err:             awserr.New("AccessDenied", "Access Denied", nil),
+			statusCode:      http.StatusForbidden,
+			bucketRegion:    "us-east-1",
+			expectedCode:    "AccessDenied",
+			expectedMessage: "Access Denied",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &request.Request{
+				Error:        tc.err,
+				HTTPResponse: &http.Response{StatusCode: tc.statusCode, Header: http.Header{}},
+			}
+			if tc.bucketRegion != "" {
+				r.HTTPResponse.Header.Set("X-Amz-Bucket-Region", tc.bucketRegion)
+			}
+
+			s3RegionRedirectHandler.Fn(r)
+
+			var awsErr awserr.Error
+			if !errors.As(r.Error, &awsErr) {
+				t.Fatalf("expected an AWS error, got %s", r.Error)
+			}
+
+			if awsErr.Code() != tc.expectedCode {
+				t.Errorf("expected code %q, got %q", tc.expectedCode, awsErr.Code())
+			}
+
+			if !strings.Contains(awsErr.Message(), tc.expectedMessage) {
+				t.Errorf("expected message to contain %q, got %q", tc.expectedMessage, awsErr.Message())
+			}
+		})
+	}
+}
+
+func TestRetryOnAWSCodes(t *testing.T) {
+	t.Parallel()
+
+	slowDown := awserr.NewRequestFailure(awserr.New("SlowDown", "Please reduce your request rate.", nil),
+		http.StatusServiceUnavailable, "")
+	unavailable := awserr.NewRequestFailure(awserr.New("", "", nil), http.StatusServiceUnavailable, "")
+	accessDenied := awserr.NewRequestFailure(awserr.New("AccessDenied", "Access Denied", nil),
+		http.StatusForbidden, "")
+
+	testCases := []struct {
+		name          string
+		errs          []error
+		timeout       time.Duration
+		expectErr     bool
+		expectedCalls int
+	}{
+		{name: "success", timeout: time.Minute, expectedCalls: 1},
+		{name: "slow down then success", errs: []error{slowDown}, timeout: time.Minute, expectedCalls: 2},
+		{name: "503 without code then success", errs: []error{unavailable}, timeout: time.Minute, expectedCalls: 2},
+		{name: "not retried", errs: []error{accessDenied}, timeout: time.Minute, expectErr: true, expectedCalls: 1},
+		{
+			name:          "timeout",
+			errs:          []error{slowDown, slowDown, slowDown, slowDown, slowDown, slowDown},
+			timeout:       time.Second,
+			expectErr:     true,
+			expectedCalls: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+
+			got, err := retryOnAWSCodes(t.Context(), tc.timeout, s3ThrottlingErrorCodes, func() (any, error) {
+				calls++
+				if calls <= len(tc.errs) {
+					return nil, tc.errs[calls-1]
+				}
+
+				return "deleted", nil
+			})
+
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+			} else if err != nil || got != "deleted" {
+				t.Fatalf("expected success, got %v, %v", got, err)
+			}
+
+			if tc.expectedCalls >= 0 && calls != tc.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
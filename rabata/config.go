@@ -1,10 +1,21 @@
 package rabata
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	awsbase "github.com/hashicorp/aws-sdk-go-base"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
 )
@@ -22,14 +33,32 @@ type Config struct {
 
 	S3ForcePathStyle bool
 
+	Anonymous         bool
+	LogUploadProgress bool
+
+	MultipartThreshold int64
+	MultipartPartSize  int64
+
+	SigningName        string
+	Partition          string
+	ChecksumValidation string
+
 	terraformVersion string
 }
 
 type AWSClient struct {
 	dnsSuffix                 string
 	region                    string
-	s3conn                    *s3.S3
-	s3connURICleaningDisabled *s3.S3
+	insecure                  bool
+	anonymous                 bool
+	logUploadProgress         bool
+	multipartThreshold        int64
+	multipartPartSize         int64
+	partition                 string
+	checksumValidation        string
+	s3ForcePathStyle          bool
+	s3conn                    s3iface.S3API
+	s3connURICleaningDisabled s3iface.S3API
 }
 
 // PartitionHostname returns a hostname with the provider domain suffix for the partition
@@ -66,16 +95,34 @@ func (c *Config) Client() (*AWSClient, error) {
 		},
 	}
 
-	sess, err := awsbase.GetSession(awsbaseConfig)
+	var (
+		sess *session.Session
+		err  error
+	)
+
+	if c.Anonymous {
+		sess, err = c.anonymousSession()
+	} else {
+		sess, err = awsbase.GetSession(awsbaseConfig)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("error configuring Terraform AWS Provider: %w", err)
 	}
 
 	dnsSuffix := getDNSSuffix(c.Region)
 
-	client := &AWSClient{
-		region:    c.Region,
-		dnsSuffix: dnsSuffix,
+	awsClient := &AWSClient{
+		region:             c.Region,
+		dnsSuffix:          dnsSuffix,
+		insecure:           c.Insecure,
+		anonymous:          c.Anonymous,
+		logUploadProgress:  c.LogUploadProgress,
+		multipartThreshold: c.MultipartThreshold,
+		multipartPartSize:  c.MultipartPartSize,
+		partition:          c.Partition,
+		checksumValidation: c.ChecksumValidation,
+		s3ForcePathStyle:   c.S3ForcePathStyle,
 	}
 
 	// Services that require multiple client configurations
@@ -83,12 +130,104 @@ func (c *Config) Client() (*AWSClient, error) {
 		Endpoint:                aws.String(c.Endpoints["s3"]),
 		S3ForcePathStyle:        aws.Bool(c.S3ForcePathStyle),
 		DisableComputeChecksums: aws.Bool(true),
+		// Operations that require a Content-MD5 header always get one,
+		// this only controls the optional checksums of object uploads and downloads.
+		S3DisableContentMD5Validation: aws.Bool(c.ChecksumValidation == checksumValidationDisabled),
+		Retryer: retryAfterRetryer{
+			DefaultRetryer: client.DefaultRetryer{NumMaxRetries: c.MaxRetries},
+		},
 	}
 
-	client.s3conn = s3.New(sess.Copy(s3Config))
+	s3conn := s3.New(sess.Copy(s3Config))
 
 	s3Config.DisableRestProtocolURICleaning = aws.Bool(true)
-	client.s3connURICleaningDisabled = s3.New(sess.Copy(s3Config))
+	s3connURICleaningDisabled := s3.New(sess.Copy(s3Config))
+
+	for _, conn := range []*s3.S3{s3conn, s3connURICleaningDisabled} {
+		// The SigV4 signer reads the service name from the client info, not from aws.Config.
+		if c.SigningName != "" {
+			conn.ClientInfo.SigningName = c.SigningName
+		}
+
+		conn.Handlers.UnmarshalError.PushBackNamed(s3RegionRedirectHandler)
+	}
+
+	awsClient.s3conn = s3conn
+	awsClient.s3connURICleaningDisabled = s3connURICleaningDisabled
+
+	return awsClient, nil
+}
+
+// retryAfterRetryer waits for the delay of the Retry-After header of throttled responses,
+// in seconds or as an HTTP date, instead of the exponential backoff of the default retryer,
+// which only adds the seconds form to its own backoff.
+type retryAfterRetryer struct {
+	client.DefaultRetryer
+}
+
+func (r retryAfterRetryer) RetryRules(req *request.Request) time.Duration {
+	if delay, ok := s3RetryAfterDelay(req.HTTPResponse); ok {
+		log.Printf("[DEBUG] Retrying %s after %s as requested by Retry-After", req.Operation.Name, delay)
+
+		return delay
+	}
+
+	return r.DefaultRetryer.RetryRules(req)
+}
+
+// s3RetryAfterDelay returns the delay of the Retry-After header of a 429 or 503 response,
+// capped to the maximum throttle delay of the default retryer.
+func s3RetryAfterDelay(resp *http.Response) (time.Duration, bool) {
+	if resp == nil ||
+		(resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+	} else {
+		return 0, false
+	}
+
+	return min(max(delay, 0), client.DefaultRetryerMaxThrottleDelay), true
+}
+
+// anonymousSession returns a session that sends unsigned requests,
+// which allows reading public buckets without credentials.
+func (c *Config) anonymousSession() (*session.Session, error) {
+	sessConfig := &aws.Config{
+		Credentials: credentials.AnonymousCredentials,
+		Region:      aws.String(c.Region),
+		MaxRetries:  aws.Int(c.MaxRetries),
+	}
+
+	if c.Insecure {
+		transport := http.DefaultTransport.(*http.Transport).Clone()      //nolint:forcetypeassert
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+		sessConfig.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	if logging.IsDebugOrHigher() {
+		sessConfig.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return session.NewSession(sessConfig)
+}
+
+// checkWritable returns an error if the client cannot modify resources.
+func (client *AWSClient) checkWritable() error {
+	if client.anonymous {
+		return errors.New("write operations are not supported when the provider is configured with anonymous = true")
+	}
 
-	return client, nil
+	return nil
 }
@@ -0,0 +1,96 @@
+package rabata
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+)
+
+func TestS3RetryAfterDelay(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		statusCode int
+		retryAfter string
+		expected   time.Duration
+		tolerance  time.Duration
+		ok         bool
+	}{
+		"seconds": {
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: "5",
+			expected:   5 * time.Second,
+			ok:         true,
+		},
+		"too many requests": {
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: "1",
+			expected:   time.Second,
+			ok:         true,
+		},
+		"http date": {
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat),
+			expected:   2 * time.Minute,
+			tolerance:  5 * time.Second,
+			ok:         true,
+		},
+		"http date in the past": {
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+			ok:         true,
+		},
+		"negative seconds": {
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: "-10",
+			ok:         true,
+		},
+		"capped": {
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: "86400",
+			expected:   client.DefaultRetryerMaxThrottleDelay,
+			ok:         true,
+		},
+		"missing": {
+			statusCode: http.StatusServiceUnavailable,
+		},
+		"unparsable": {
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: "soon",
+		},
+		"other status code": {
+			statusCode: http.StatusInternalServerError,
+			retryAfter: "5",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &http.Response{StatusCode: tc.statusCode, Header: http.Header{}}
+			if tc.retryAfter != "" {
+				resp.Header.Set("Retry-After", tc.retryAfter)
+			}
+
+			got, ok := s3RetryAfterDelay(resp)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %t, got %t", tc.ok, ok)
+			}
+
+			if diff := (got - tc.expected).Abs(); diff > tc.tolerance {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestS3RetryAfterDelayNilResponse(t *testing.T) {
+	t.Parallel()
+
+	if got, ok := s3RetryAfterDelay(nil); ok || got != 0 {
+		t.Errorf("expected no delay, got %s, %t", got, ok)
+	}
+}
@@ -35,8 +35,16 @@ func dataSourceRabataS3Bucket() *schema.Resource {
 				Computed: true,
 			},
 			"region": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"skip_region"},
+			},
+			"skip_region": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"region"},
 			},
 		},
 	}
@@ -61,7 +69,7 @@ func dataSourceRabataS3BucketRead(ctx context.Context, d *schema.ResourceData, m
 
 	d.SetId(bucket)
 	a := arn.ARN{
-		Partition: "aws",
+		Partition: awsClient.partition,
 		Service:   "s3",
 		Resource:  bucket,
 	}.String()
@@ -71,8 +79,13 @@ func dataSourceRabataS3BucketRead(ctx context.Context, d *schema.ResourceData, m
 
 	d.Set("bucket_domain_name", bucketDomainName) //nolint:errcheck
 
-	err = bucketLocation(ctx, awsClient, d, bucket)
-	if err != nil {
+	// A configured region skips the lookup, for backends that do not implement the location API.
+	if _, ok := d.GetOk("region"); ok {
+		log.Printf("[DEBUG] Using configured region for S3 bucket: %s", bucket)
+	} else if d.Get("skip_region").(bool) { //nolint:forcetypeassert
+		log.Printf("[DEBUG] Using provider region for S3 bucket: %s", bucket)
+		d.Set("region", awsClient.region) //nolint:errcheck
+	} else if err := bucketLocation(ctx, awsClient, d, bucket); err != nil {
 		return diag.Errorf("error getting S3 Bucket location: %s", err)
 	}
 
@@ -91,7 +104,7 @@ func bucketLocation(ctx context.Context, client *AWSClient, d *schema.ResourceDa
 			// is not compatible with many non-AWS implementations. Instead, pass
 			// the provider s3_force_path_style configuration, which defaults to
 			// false, but allows override.
-			r.Config.S3ForcePathStyle = client.s3conn.Config.S3ForcePathStyle
+			r.Config.S3ForcePathStyle = aws.Bool(client.s3ForcePathStyle)
 		},
 	)
 	if err != nil {
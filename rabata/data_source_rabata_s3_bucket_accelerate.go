@@ -0,0 +1,71 @@
+package rabata
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// s3BucketAccelerateStatusUnsupported is the status of buckets on backends without transfer acceleration.
+const s3BucketAccelerateStatusUnsupported = "Unsupported"
+
+func dataSourceRabataS3BucketAccelerate() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3BucketAccelerateRead,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"expected_bucket_owner": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3BucketAccelerateRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+
+	input := &s3.GetBucketAccelerateConfigurationInput{
+		Bucket: aws.String(bucket),
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	d.SetId(bucket)
+
+	out, err := conn.GetBucketAccelerateConfigurationWithContext(ctx, input)
+	if isS3NotImplementedErr(err) {
+		log.Printf("[DEBUG] S3 Bucket (%s) transfer acceleration is not supported: %s", bucket, err)
+		d.Set("status", s3BucketAccelerateStatusUnsupported) //nolint:errcheck
+
+		return nil
+	}
+
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return diag.Errorf("error reading S3 Bucket (%s) accelerate configuration: %s", bucket, err)
+	}
+
+	// The status is empty if acceleration has never been configured on the bucket.
+	d.Set("status", aws.StringValue(out.Status)) //nolint:errcheck
+
+	return nil
+}
@@ -0,0 +1,357 @@
+package rabata
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceRabataS3BucketDetails reads the bucket subresources in one data source.
+// A subresource that is not configured, or not implemented by the endpoint, is left empty.
+func dataSourceRabataS3BucketDetails() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3BucketDetailsRead,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"policy": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"cors_rule": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"allowed_headers": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"allowed_methods": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"allowed_origins": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"expose_headers": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"max_age_seconds": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"lifecycle_rule": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"abort_incomplete_multipart_upload_days": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"expiration": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"date": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"days": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"expired_object_delete_marker": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+								},
+							},
+						},
+						"noncurrent_version_expiration": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"days": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+								},
+							},
+						},
+						"transition": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"date": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"days": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"storage_class": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"versioning": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mfa_delete": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"server_side_encryption_configuration": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"sse_algorithm": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"kms_master_key_id": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"bucket_key_enabled": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"tags": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3BucketDetailsRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+
+	_, err := conn.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return diag.Errorf("failed getting S3 bucket: %s Bucket: %q", err, bucket)
+	}
+
+	d.SetId(bucket)
+
+	policy, err := conn.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	if !isS3BucketDetailNotConfiguredErr(err, "NoSuchBucketPolicy") {
+		if err != nil {
+			return diag.Errorf("error getting S3 Bucket (%s) policy: %s", bucket, err)
+		}
+
+		d.Set("policy", aws.StringValue(policy.Policy)) //nolint:errcheck
+	}
+
+	cors, err := conn.GetBucketCorsWithContext(ctx, &s3.GetBucketCorsInput{
+		Bucket: aws.String(bucket),
+	})
+	if !isS3BucketDetailNotConfiguredErr(err, "NoSuchCORSConfiguration") {
+		if err != nil {
+			return diag.Errorf("error getting S3 Bucket (%s) CORS configuration: %s", bucket, err)
+		}
+
+		if err := d.Set("cors_rule", flattenS3CORSRules(cors.CORSRules)); err != nil {
+			return diag.Errorf("error setting cors_rule: %s", err)
+		}
+	}
+
+	rules, err := getS3BucketLifecycleRules(ctx, conn, bucket)
+	if !isS3BucketDetailNotConfiguredErr(err, "") {
+		if err != nil {
+			return diag.Errorf("error getting S3 Bucket (%s) lifecycle configuration: %s", bucket, err)
+		}
+
+		if err := d.Set("lifecycle_rule", flattenS3LifecycleRules(rules)); err != nil {
+			return diag.Errorf("error setting lifecycle_rule: %s", err)
+		}
+	}
+
+	versioning, err := conn.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucket),
+	})
+	if !isS3BucketDetailNotConfiguredErr(err, "") {
+		if err != nil {
+			return diag.Errorf("error getting S3 Bucket (%s) versioning: %s", bucket, err)
+		}
+
+		//nolint:errcheck
+		d.Set("versioning", []any{map[string]any{
+			"status":     aws.StringValue(versioning.Status),
+			"mfa_delete": aws.StringValue(versioning.MFADelete),
+		}})
+	}
+
+	encryption, err := conn.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+	})
+	if !isS3BucketDetailNotConfiguredErr(err, s3ErrCodeServerSideEncryptionConfigurationNotFound) {
+		if err != nil {
+			return diag.Errorf("error getting S3 Bucket (%s) encryption: %s", bucket, err)
+		}
+
+		if err := d.Set("server_side_encryption_configuration",
+			flattenS3ServerSideEncryptionConfiguration(encryption.ServerSideEncryptionConfiguration)); err != nil {
+			return diag.Errorf("error setting server_side_encryption_configuration: %s", err)
+		}
+	}
+
+	tags, err := getS3BucketTags(ctx, conn, bucket)
+	if !isS3BucketDetailNotConfiguredErr(err, "NoSuchTagSet") {
+		if err != nil {
+			return diag.Errorf("error getting S3 Bucket (%s) tags: %s", bucket, err)
+		}
+
+		if err := d.Set("tags", tags); err != nil {
+			return diag.Errorf("error setting tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// isS3BucketDetailNotConfiguredErr returns true if err is the notConfiguredCode error
+// of a subresource that is not set, or the endpoint does not implement the subresource.
+func isS3BucketDetailNotConfiguredErr(err error, notConfiguredCode string) bool {
+	if err == nil {
+		return false
+	}
+
+	if isS3NotImplementedErr(err) || (notConfiguredCode != "" && isAWSErr(err, notConfiguredCode, "")) {
+		log.Printf("[DEBUG] S3 Bucket subresource not configured or not implemented: %s", err)
+
+		return true
+	}
+
+	return false
+}
+
+func getS3BucketTags(ctx context.Context, conn s3iface.S3API, bucket string) (map[string]any, error) {
+	out, err := conn.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]any, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return tags, nil
+}
+
+func flattenS3CORSRules(rules []*s3.CORSRule) []any {
+	results := make([]any, 0, len(rules))
+
+	for _, rule := range rules {
+		results = append(results, map[string]any{
+			"allowed_headers": aws.StringValueSlice(rule.AllowedHeaders),
+			"allowed_methods": aws.StringValueSlice(rule.AllowedMethods),
+			"allowed_origins": aws.StringValueSlice(rule.AllowedOrigins),
+			"expose_headers":  aws.StringValueSlice(rule.ExposeHeaders),
+			"max_age_seconds": int(aws.Int64Value(rule.MaxAgeSeconds)),
+		})
+	}
+
+	return results
+}
+
+func flattenS3LifecycleRules(rules []*s3.LifecycleRule) []any {
+	results := make([]any, 0, len(rules))
+
+	for _, rule := range rules {
+		prefix := aws.StringValue(rule.Prefix)
+		if rule.Filter != nil && rule.Filter.Prefix != nil {
+			prefix = aws.StringValue(rule.Filter.Prefix)
+		}
+
+		m := map[string]any{
+			"id":                            aws.StringValue(rule.ID),
+			"prefix":                        prefix,
+			"enabled":                       aws.StringValue(rule.Status) == s3.ExpirationStatusEnabled,
+			"expiration":                    flattenS3LifecycleExpiration(rule.Expiration),
+			"noncurrent_version_expiration": flattenS3NoncurrentVersionExpiration(rule.NoncurrentVersionExpiration),
+			"transition":                    flattenS3LifecycleTransitions(rule.Transitions),
+		}
+
+		if rule.AbortIncompleteMultipartUpload != nil {
+			m["abort_incomplete_multipart_upload_days"] = int(
+				aws.Int64Value(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation))
+		}
+
+		results = append(results, m)
+	}
+
+	return results
+}
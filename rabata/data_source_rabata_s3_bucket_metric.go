@@ -0,0 +1,187 @@
+package rabata
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// s3ErrCodeNoSuchConfiguration is returned by GetBucketMetricsConfiguration for an unknown id.
+const s3ErrCodeNoSuchConfiguration = "NoSuchConfiguration"
+
+func dataSourceRabataS3BucketMetric() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3BucketMetricRead,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringLenBetween(1, 64), //nolint:mnd
+			},
+			"metrics": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tags": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"access_point": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3BucketMetricRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	name := d.Get("name").(string)     //nolint:forcetypeassert
+
+	var (
+		configurations []*s3.MetricsConfiguration
+		err            error
+	)
+
+	if name != "" {
+		configurations, err = getS3BucketMetricsConfiguration(ctx, conn, bucket, name)
+	} else {
+		configurations, err = listS3BucketMetricsConfigurations(ctx, conn, bucket)
+	}
+
+	if err != nil {
+		return diag.Errorf("error reading S3 Bucket (%s) metrics configurations: %s", bucket, err)
+	}
+
+	metrics := make([]any, 0, len(configurations))
+	for _, configuration := range configurations {
+		metrics = append(metrics, flattenS3MetricsConfiguration(configuration))
+	}
+
+	if name != "" {
+		d.SetId(bucket + ":" + name)
+	} else {
+		d.SetId(bucket)
+	}
+
+	if err := d.Set("metrics", metrics); err != nil {
+		return diag.Errorf("error setting metrics: %s", err)
+	}
+
+	return nil
+}
+
+// getS3BucketMetricsConfiguration returns the named configuration, or none if it does not exist.
+func getS3BucketMetricsConfiguration(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket, name string,
+) ([]*s3.MetricsConfiguration, error) {
+	out, err := conn.GetBucketMetricsConfigurationWithContext(ctx, &s3.GetBucketMetricsConfigurationInput{
+		Bucket: aws.String(bucket),
+		Id:     aws.String(name),
+	})
+	if isAWSErr(err, s3ErrCodeNoSuchConfiguration, "") {
+		log.Printf("[DEBUG] S3 Bucket (%s) metrics configuration (%s) not found", bucket, name)
+
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if out.MetricsConfiguration == nil {
+		return nil, nil
+	}
+
+	return []*s3.MetricsConfiguration{out.MetricsConfiguration}, nil
+}
+
+func listS3BucketMetricsConfigurations(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket string,
+) ([]*s3.MetricsConfiguration, error) {
+	var configurations []*s3.MetricsConfiguration
+
+	input := &s3.ListBucketMetricsConfigurationsInput{
+		Bucket: aws.String(bucket),
+	}
+
+	for {
+		out, err := conn.ListBucketMetricsConfigurationsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		configurations = append(configurations, out.MetricsConfigurationList...)
+
+		if !aws.BoolValue(out.IsTruncated) || aws.StringValue(out.NextContinuationToken) == "" {
+			break
+		}
+
+		input.ContinuationToken = out.NextContinuationToken
+	}
+
+	return configurations, nil
+}
+
+func flattenS3MetricsConfiguration(configuration *s3.MetricsConfiguration) map[string]any {
+	m := map[string]any{
+		"name": aws.StringValue(configuration.Id),
+	}
+
+	filter := configuration.Filter
+	if filter == nil {
+		return m
+	}
+
+	tags := make(map[string]any)
+
+	if filter.Tag != nil {
+		tags[aws.StringValue(filter.Tag.Key)] = aws.StringValue(filter.Tag.Value)
+	}
+
+	m["prefix"] = aws.StringValue(filter.Prefix)
+	m["access_point"] = aws.StringValue(filter.AccessPointArn)
+
+	if and := filter.And; and != nil {
+		m["prefix"] = aws.StringValue(and.Prefix)
+		m["access_point"] = aws.StringValue(and.AccessPointArn)
+
+		for _, tag := range and.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+
+	m["tags"] = tags
+
+	return m
+}
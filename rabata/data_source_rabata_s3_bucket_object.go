@@ -3,18 +3,32 @@ package rabata
 import (
 	"bytes"
 	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// defaultS3ObjectMaxBodySize is the default maximum size of an object body read into state.
+const defaultS3ObjectMaxBodySize = 64 << 20
+
+// s3MD5ETagRegexp matches the ETag of objects uploaded in a single part.
+var s3MD5ETagRegexp = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
 func dataSourceRabataS3BucketObject() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceRabataS3BucketObjectRead,
@@ -52,10 +66,24 @@ func dataSourceRabataS3BucketObject() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"error_if_missing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
 			"etag": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"expected_bucket_owner": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"request_payer": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(s3.RequestPayer_Values(), false),
+			},
 			"expiration": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -64,6 +92,16 @@ func dataSourceRabataS3BucketObject() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"body_skipped": {
+				Type:     schema.TypeBool,
+				Computed: true,
+				Description: "Whether `body` was not read: the content type is not text, `fetch_body` is false " +
+					"or the object is `not_modified`. Tells these cases from an empty object, `body` is empty in both.",
+			},
+			"is_delete_marker": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
 			"key": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -72,6 +110,32 @@ func dataSourceRabataS3BucketObject() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"fetch_body": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"if_modified_since": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"if_none_match": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"not_modified": {
+				Type:     schema.TypeBool,
+				Computed: true,
+				Description: "Whether the object was not modified since `if_modified_since` or still matches " +
+					"`if_none_match`. The metadata attributes are still set, but `body` is not read and is empty.",
+			},
+			"max_body_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultS3ObjectMaxBodySize,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 			"metadata": {
 				Type:     schema.TypeMap,
 				Computed: true,
@@ -81,6 +145,20 @@ func dataSourceRabataS3BucketObject() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"read_retry_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"server_side_encryption": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"bucket_key_enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
 			"sse_kms_key_id": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -99,14 +177,16 @@ func dataSourceRabataS3BucketObject() *schema.Resource {
 }
 
 func dataSourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
+	conn := awsClient.s3conn
 
 	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
 	key := d.Get("key").(string)       //nolint:forcetypeassert
 
 	input := s3.HeadObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: s3RequestPayer(d),
 	}
 
 	if v, ok := d.GetOk("range"); ok {
@@ -117,6 +197,20 @@ func dataSourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceD
 		input.VersionId = aws.String(v.(string)) //nolint:forcetypeassert
 	}
 
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	if v, ok := d.GetOk("if_modified_since"); ok {
+		t, _ := time.Parse(time.RFC3339, v.(string)) //nolint:forcetypeassert
+		input.IfModifiedSince = aws.Time(t)
+	}
+
+	if v, ok := d.GetOk("if_none_match"); ok {
+		input.IfNoneMatch = aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
 	versionText := ""
 	uniqueID := bucket + "/" + key
 
@@ -128,19 +222,62 @@ func dataSourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceD
 
 	log.Printf("[DEBUG] Reading S3 Bucket Object: %s", input)
 
-	out, err := conn.HeadObjectWithContext(ctx, &input)
-	if err != nil {
-		return diag.Errorf("Failed getting S3 object: %s Bucket: %q Object: %q", err, bucket, key)
+	readRetryTimeout := time.Duration(d.Get("read_retry_timeout").(int)) * time.Second //nolint:forcetypeassert
+
+	errorIfMissing := d.Get("error_if_missing").(bool) //nolint:forcetypeassert
+
+	out, err := headS3ObjectWithRetry(ctx, conn, &input, readRetryTimeout)
+
+	var deleteMarkerErr *s3DeleteMarkerError
+	if errors.As(err, &deleteMarkerErr) {
+		if errorIfMissing {
+			return diag.Errorf("Requested S3 object %q%s is deleted (delete marker, version %q)",
+				bucket+"/"+key, versionText, deleteMarkerErr.VersionID)
+		}
+
+		log.Printf("[WARN] S3 object %s is deleted (delete marker, version %q)", uniqueID, deleteMarkerErr.VersionID)
+
+		d.SetId(uniqueID)
+		d.Set("is_delete_marker", true)                //nolint:errcheck
+		d.Set("version_id", deleteMarkerErr.VersionID) //nolint:errcheck
+
+		return nil
 	}
 
-	if out.DeleteMarker != nil && *out.DeleteMarker {
-		return diag.Errorf("Requested S3 object %q%s has been deleted",
-			bucket+key, versionText)
+	// The object has not changed since if_modified_since or still matches if_none_match,
+	// so its body is not read. The data source state is rebuilt on every read,
+	// so its metadata is read again without the conditions.
+	notModified := isAWSErrRequestFailureStatusCode(err, http.StatusNotModified)
+	if notModified {
+		log.Printf("[INFO] S3 object %s not modified", uniqueID)
+
+		input.IfModifiedSince = nil
+		input.IfNoneMatch = nil
+
+		out, err = headS3ObjectWithRetry(ctx, conn, &input, readRetryTimeout)
+	}
+
+	if !errorIfMissing && isAWSErrRequestFailureStatusCode(err, http.StatusNotFound) {
+		log.Printf("[WARN] S3 object %s not found", uniqueID)
+
+		d.SetId(uniqueID)
+		d.Set("is_delete_marker", false) //nolint:errcheck
+
+		return nil
+	}
+
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return diag.Errorf("Failed getting S3 object: %s Bucket: %q Object: %q", err, bucket, key)
 	}
 
 	log.Printf("[DEBUG] Received S3 object: %s", out)
 
 	d.SetId(uniqueID)
+	d.Set("is_delete_marker", false)   //nolint:errcheck
+	d.Set("not_modified", notModified) //nolint:errcheck
+	d.Set("body_skipped", false)       //nolint:errcheck
 
 	d.Set("cache_control", out.CacheControl)             //nolint:errcheck
 	d.Set("content_disposition", out.ContentDisposition) //nolint:errcheck
@@ -149,13 +286,15 @@ func dataSourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceD
 	d.Set("content_length", out.ContentLength)           //nolint:errcheck
 	d.Set("content_type", out.ContentType)               //nolint:errcheck
 	// See https://forums.aws.amazon.com/thread.jspa?threadID=44003
-	d.Set("etag", strings.Trim(*out.ETag, `"`))                   //nolint:errcheck
-	d.Set("expiration", out.Expiration)                           //nolint:errcheck
-	d.Set("expires", out.Expires)                                 //nolint:errcheck
-	d.Set("last_modified", out.LastModified.Format(time.RFC1123)) //nolint:errcheck
-	d.Set("metadata", pointersMapToStringList(out.Metadata))      //nolint:errcheck
-	d.Set("sse_kms_key_id", out.SSEKMSKeyId)                      //nolint:errcheck
-	d.Set("version_id", out.VersionId)                            //nolint:errcheck
+	d.Set("etag", strings.Trim(*out.ETag, `"`))                      //nolint:errcheck
+	d.Set("expiration", out.Expiration)                              //nolint:errcheck
+	d.Set("expires", out.Expires)                                    //nolint:errcheck
+	d.Set("last_modified", out.LastModified.Format(time.RFC1123))    //nolint:errcheck
+	d.Set("metadata", pointersMapToStringList(out.Metadata))         //nolint:errcheck
+	d.Set("server_side_encryption", out.ServerSideEncryption)        //nolint:errcheck
+	d.Set("bucket_key_enabled", aws.BoolValue(out.BucketKeyEnabled)) //nolint:errcheck
+	d.Set("sse_kms_key_id", out.SSEKMSKeyId)                         //nolint:errcheck
+	d.Set("version_id", out.VersionId)                               //nolint:errcheck
 
 	// The "STANDARD" (which is also the default) storage
 	// class when set would not be included in the results.
@@ -166,6 +305,12 @@ func dataSourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceD
 
 	d.Set("storage_class", storageClass) //nolint:errcheck
 
+	if notModified {
+		d.Set("body_skipped", true) //nolint:errcheck
+
+		return nil
+	}
+
 	if !isContentTypeAllowed(out.ContentType) {
 		var contentType string
 		if out.ContentType == nil {
@@ -177,12 +322,24 @@ func dataSourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceD
 		log.Printf("[INFO] Ignoring body of S3 object %s with Content-Type %q",
 			uniqueID, contentType)
 
+		// Tells an object that is not text from an empty object, both have an empty body.
+		d.Set("body_skipped", true) //nolint:errcheck
+
+		return nil
+	}
+
+	if !d.Get("fetch_body").(bool) { //nolint:forcetypeassert
+		log.Printf("[INFO] Skipping body of S3 object %s, fetch_body is false", uniqueID)
+
+		d.Set("body_skipped", true) //nolint:errcheck
+
 		return nil
 	}
 
 	getObjectInput := s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: s3RequestPayer(d),
 	}
 	if v, ok := d.GetOk("range"); ok {
 		getObjectInput.Range = aws.String(v.(string)) //nolint:forcetypeassert
@@ -192,25 +349,151 @@ func dataSourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceD
 		getObjectInput.VersionId = out.VersionId
 	}
 
+	if expectedBucketOwner != "" {
+		getObjectInput.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
 	getObjectOutput, err := conn.GetObjectWithContext(ctx, &getObjectInput)
 	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
 		return diag.Errorf("Failed getting S3 object: %s", err)
 	}
 
+	defer getObjectOutput.Body.Close()
+
+	maxBodySize := int64(d.Get("max_body_size").(int)) //nolint:forcetypeassert
 	buf := new(bytes.Buffer)
 
-	bytesRead, err := buf.ReadFrom(getObjectOutput.Body)
+	// Read one byte more than allowed to tell an object of exactly max_body_size bytes from a larger one.
+	bytesRead, err := buf.ReadFrom(io.LimitReader(getObjectOutput.Body, maxBodySize+1))
 	if err != nil {
 		return diag.Errorf("Failed reading content of S3 object (%s): %s",
 			uniqueID, err)
 	}
 
+	if bytesRead > maxBodySize {
+		return diag.Errorf("S3 object (%s) body is larger than max_body_size (%d bytes), "+
+			"use range to read a part of it or rabata_s3_object_download to save it to a file",
+			uniqueID, maxBodySize)
+	}
+
+	if awsClient.checksumValidation == checksumValidationWhenSupported && getObjectInput.Range == nil {
+		if err := validateS3ObjectBodyMD5(buf.Bytes(), getObjectOutput); err != nil {
+			return diag.Errorf("Failed validating content of S3 object (%s): %s", uniqueID, err)
+		}
+	}
+
 	log.Printf("[INFO] Saving %d bytes from S3 object %s", bytesRead, uniqueID)
 	d.Set("body", buf.String()) //nolint:errcheck
 
 	return nil
 }
 
+// validateS3ObjectBodyMD5 compares the body with the object ETag. The ETag is only the MD5 of the body
+// for objects uploaded in a single part without SSE-KMS or SSE-C, other objects are not validated.
+func validateS3ObjectBodyMD5(body []byte, out *s3.GetObjectOutput) error {
+	etag := strings.Trim(aws.StringValue(out.ETag), `"`)
+	if !s3MD5ETagRegexp.MatchString(etag) ||
+		out.SSECustomerAlgorithm != nil ||
+		aws.StringValue(out.ServerSideEncryption) == s3.ServerSideEncryptionAwsKms {
+		return nil
+	}
+
+	sum := md5.Sum(body) //nolint:gosec
+	if actual := hex.EncodeToString(sum[:]); actual != strings.ToLower(etag) {
+		return fmt.Errorf("body MD5 %s does not match ETag %s", actual, etag)
+	}
+
+	return nil
+}
+
+// s3DeleteMarkerError is returned by headS3Object when the requested object
+// (or object version) is a delete marker.
+type s3DeleteMarkerError struct {
+	VersionID string
+	Err       error
+}
+
+func (e *s3DeleteMarkerError) Error() string {
+	return fmt.Sprintf("object version %q is a delete marker: %s", e.VersionID, e.Err)
+}
+
+func (e *s3DeleteMarkerError) Unwrap() error {
+	return e.Err
+}
+
+// headS3Object calls HeadObject and returns an *s3DeleteMarkerError if the object is a delete marker.
+// S3 responds to HEAD of a delete marker with HTTP 404 (latest version) or 405 (specific version),
+// and the x-amz-delete-marker and x-amz-version-id headers are only available on the raw response.
+func headS3Object(ctx context.Context, conn s3iface.S3API, input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	req, out := conn.HeadObjectRequest(input)
+	req.SetContext(ctx)
+
+	err := req.Send()
+
+	if err != nil && req.HTTPResponse != nil && req.HTTPResponse.Header.Get("X-Amz-Delete-Marker") == "true" {
+		return nil, &s3DeleteMarkerError{
+			VersionID: req.HTTPResponse.Header.Get("X-Amz-Version-Id"),
+			Err:       err,
+		}
+	}
+
+	if err == nil && aws.BoolValue(out.DeleteMarker) {
+		return nil, &s3DeleteMarkerError{
+			VersionID: aws.StringValue(out.VersionId),
+			Err:       errors.New("delete marker"),
+		}
+	}
+
+	return out, err
+}
+
+// headS3ObjectWithRetry retries HeadObject on HTTP 404 for up to timeout
+// to tolerate read-after-write lag. A zero timeout disables retries.
+func headS3ObjectWithRetry(
+	ctx context.Context,
+	conn s3iface.S3API,
+	input *s3.HeadObjectInput,
+	timeout time.Duration,
+) (*s3.HeadObjectOutput, error) {
+	if timeout <= 0 {
+		return headS3Object(ctx, conn, input)
+	}
+
+	var out *s3.HeadObjectOutput
+
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		var err error
+
+		out, err = headS3Object(ctx, conn, input)
+
+		var deleteMarkerErr *s3DeleteMarkerError
+		if errors.As(err, &deleteMarkerErr) {
+			return retry.NonRetryableError(err)
+		}
+
+		if isAWSErrRequestFailureStatusCode(err, http.StatusNotFound) || isAWSErr(err, s3.ErrCodeNoSuchKey, "") {
+			log.Printf("[DEBUG] S3 Bucket (%s) Object (%s) not found yet, retrying",
+				aws.StringValue(input.Bucket), aws.StringValue(input.Key))
+
+			return retry.RetryableError(err)
+		}
+
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+
+		return nil
+	})
+
+	if isResourceTimeoutError(err) {
+		out, err = headS3Object(ctx, conn, input)
+	}
+
+	return out, err
+}
+
 // This is to prevent potential issues w/ binary files
 // and generally unprintable characters
 // See https://github.com/hashicorp/terraform/pull/3858#issuecomment-156856738
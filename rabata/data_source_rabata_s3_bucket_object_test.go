@@ -0,0 +1,133 @@
+package rabata
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceRabataS3BucketObjectChecksumValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		checksumValidation string
+		corruptBodies      bool
+		expectErr          bool
+	}{
+		{name: "when required", checksumValidation: checksumValidationWhenRequired, corruptBodies: true},
+		{name: "disabled", checksumValidation: checksumValidationDisabled, corruptBodies: true},
+		{name: "when supported", checksumValidation: checksumValidationWhenSupported},
+		{
+			name:               "when supported with a corrupted body",
+			checksumValidation: checksumValidationWhenSupported,
+			corruptBodies:      true,
+			expectErr:          true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := newFakeS3()
+			conn.corruptBodies = tc.corruptBodies
+
+			_, err := conn.PutObjectWithContext(t.Context(), &s3.PutObjectInput{
+				Bucket:      aws.String("tf-test"),
+				Key:         aws.String("k"),
+				Body:        strings.NewReader("content"),
+				ContentType: aws.String("text/plain"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := dataSourceRabataS3BucketObject()
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]any{"bucket": "tf-test", "key": "k"})
+			meta := &AWSClient{s3conn: conn, checksumValidation: tc.checksumValidation}
+
+			diags := dataSourceRabataS3BucketObjectRead(context.Background(), d, meta)
+			if diags.HasError() != tc.expectErr {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, diags)
+			}
+
+			if !tc.expectErr && !tc.corruptBodies && d.Get("body") != "content" {
+				t.Errorf("expected body %q, got %q", "content", d.Get("body"))
+			}
+		})
+	}
+}
+
+func TestDataSourceRabataS3BucketObjectBodySkipped(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		content           string
+		contentType       string
+		config            map[string]any
+		expectBodySkipped bool
+		expectedBody      string
+	}{
+		{name: "text", content: "content", contentType: "text/plain", expectedBody: "content"},
+		{name: "empty text", contentType: "text/plain"},
+		{name: "not text", content: "content", contentType: "image/png", expectBodySkipped: true},
+		{
+			name:              "fetch body disabled",
+			content:           "content",
+			contentType:       "text/plain",
+			config:            map[string]any{"fetch_body": false},
+			expectBodySkipped: true,
+		},
+		{
+			name:        "not modified",
+			content:     "content",
+			contentType: "text/plain",
+			// The MD5 of "content".
+			config:            map[string]any{"if_none_match": `"9a0364b9e99bb480dd25e1f0284c8555"`},
+			expectBodySkipped: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := newFakeS3()
+
+			_, err := conn.PutObjectWithContext(t.Context(), &s3.PutObjectInput{
+				Bucket:      aws.String("tf-test"),
+				Key:         aws.String("k"),
+				Body:        strings.NewReader(tc.content),
+				ContentType: aws.String(tc.contentType),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := map[string]any{"bucket": "tf-test", "key": "k"}
+			maps.Copy(config, tc.config)
+
+			r := dataSourceRabataS3BucketObject()
+			d := schema.TestResourceDataRaw(t, r.Schema, config)
+
+			if diags := dataSourceRabataS3BucketObjectRead(context.Background(), d, &AWSClient{s3conn: conn}); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if got := d.Get("body_skipped").(bool); got != tc.expectBodySkipped { //nolint:forcetypeassert
+				t.Errorf("expected body_skipped %t, got %t", tc.expectBodySkipped, got)
+			}
+
+			if got := d.Get("body"); got != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, got)
+			}
+		})
+	}
+}
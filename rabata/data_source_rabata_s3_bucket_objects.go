@@ -2,16 +2,36 @@ package rabata
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const keyRequestPageSize = 1000
 
+const (
+	// defaultS3ObjectsEnrichMaxObjects is the default number of keys that enrich sends HeadObject for.
+	defaultS3ObjectsEnrichMaxObjects = 1000
+	// s3ObjectsEnrichMaxConcurrency caps max_concurrency for the HeadObject calls of enrich.
+	s3ObjectsEnrichMaxConcurrency = 16
+)
+
 func dataSourceRabataS3BucketObjects() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceRabataS3BucketObjectsRead,
@@ -22,8 +42,21 @@ func dataSourceRabataS3BucketObjects() *schema.Resource {
 				Required: true,
 			},
 			"prefix": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"prefixes"},
+			},
+			"prefixes": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{"prefix", "continuation_token"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+			},
+			"max_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4, //nolint:mnd
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"delimiter": {
 				Type:     schema.TypeString,
@@ -42,10 +75,52 @@ func dataSourceRabataS3BucketObjects() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"continuation_token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"prefixes"},
+			},
+			"match": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"regex"},
+				ValidateFunc:  validateGlobPattern,
+			},
+			"regex": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"match"},
+				ValidateFunc:  validation.StringIsValidRegExp,
+			},
+			"exclude_folders": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"enrich": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"enrich_max_objects": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultS3ObjectsEnrichMaxObjects,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
 			"fetch_owner": {
 				Type:     schema.TypeBool,
 				Optional: true,
 			},
+			"expected_bucket_owner": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"request_payer": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(s3.RequestPayer_Values(), false),
+			},
 			"keys": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -61,6 +136,15 @@ func dataSourceRabataS3BucketObjects() *schema.Resource {
 				Computed: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"next_continuation_token": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"metadata_by_key": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
@@ -74,7 +158,8 @@ func dataSourceRabataS3BucketObjectsRead(ctx context.Context, d *schema.Resource
 	d.SetId(id.UniqueId())
 
 	listInput := s3.ListObjectsV2Input{
-		Bucket: aws.String(bucket),
+		Bucket:       aws.String(bucket),
+		RequestPayer: s3RequestPayer(d),
 	}
 
 	if prefix != "" {
@@ -89,45 +174,278 @@ func dataSourceRabataS3BucketObjectsRead(ctx context.Context, d *schema.Resource
 		listInput.EncodingType = aws.String(s.(string)) //nolint:forcetypeassert
 	}
 
-	// "listInput.MaxKeys" refers to max keys returned in a single request
-	// (i.e., page size), not the total number of keys returned if you page
-	// through the results. "maxKeys" does refer to total keys returned.
 	maxKeys := int64(d.Get("max_keys").(int)) //nolint:forcetypeassert
-	if maxKeys <= keyRequestPageSize {
-		listInput.MaxKeys = aws.Int64(maxKeys)
-	}
 
 	if s, ok := d.GetOk("start_after"); ok {
 		listInput.StartAfter = aws.String(s.(string)) //nolint:forcetypeassert
 	}
 
+	if s, ok := d.GetOk("continuation_token"); ok {
+		listInput.ContinuationToken = aws.String(s.(string)) //nolint:forcetypeassert
+	}
+
 	if b, ok := d.GetOk("fetch_owner"); ok {
 		listInput.FetchOwner = aws.Bool(b.(bool)) //nolint:forcetypeassert
 	}
 
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		listInput.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
 	var (
-		commonPrefixes []string
-		keys           []string
-		owners         []string
+		result *s3ObjectsListing
+		err    error
 	)
 
+	if v, ok := d.GetOk("prefixes"); ok {
+		prefixes := expandStringSet(v.(*schema.Set))     //nolint:forcetypeassert
+		maxConcurrency := d.Get("max_concurrency").(int) //nolint:forcetypeassert
+
+		result, err = listS3BucketObjectsByPrefixes(ctx, conn, listInput, prefixes, maxKeys, maxConcurrency)
+	} else {
+		result, err = listS3BucketObjects(ctx, conn, listInput, maxKeys)
+	}
+
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return diag.Errorf("error listing S3 Bucket (%s) Objects: %s", bucket, err)
+	}
+
+	// Folder markers are the zero-byte objects with a key ending in "/" that
+	// consoles and tools create to show empty folders.
+	if d.Get("exclude_folders").(bool) { //nolint:forcetypeassert
+		result.filterKeys(func(key string) bool {
+			_, ok := result.folderMarkers[key]
+
+			return !ok
+		})
+	}
+
+	// S3 only filters by prefix, so match and regex are applied to the listed keys.
+	if v, ok := d.GetOk("match"); ok {
+		pattern := v.(string) //nolint:forcetypeassert
+
+		result.filterKeys(func(key string) bool {
+			matched, _ := path.Match(pattern, key)
+
+			return matched
+		})
+	} else if v, ok := d.GetOk("regex"); ok {
+		re := regexp.MustCompile(v.(string)) //nolint:forcetypeassert
+
+		result.filterKeys(re.MatchString)
+	}
+
+	if err := d.Set("common_prefixes", result.commonPrefixes); err != nil {
+		return diag.Errorf("error setting common_prefixes: %s", err)
+	}
+
+	if err := d.Set("keys", result.keys); err != nil {
+		return diag.Errorf("error setting keys: %s", err)
+	}
+
+	if err := d.Set("owners", result.owners); err != nil {
+		return diag.Errorf("error setting owners: %s", err)
+	}
+
+	d.Set("next_continuation_token", result.nextContinuationToken) //nolint:errcheck
+
+	metadataByKey := make(map[string]any)
+
+	if d.Get("enrich").(bool) { //nolint:forcetypeassert
+		maxObjects := d.Get("enrich_max_objects").(int) //nolint:forcetypeassert
+		if len(result.keys) > maxObjects {
+			return diag.Errorf("enrich: %d keys listed in S3 Bucket (%s), more than enrich_max_objects (%d); "+
+				"narrow the listing with prefix, match or max_keys", len(result.keys), bucket, maxObjects)
+		}
+
+		maxConcurrency := min(d.Get("max_concurrency").(int), s3ObjectsEnrichMaxConcurrency) //nolint:forcetypeassert
+
+		metadataByKey, err = headS3BucketObjectsMetadata(
+			ctx, conn, bucket, expectedBucketOwner, listInput.RequestPayer, result.keys, maxConcurrency)
+		if err != nil {
+			err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+			return diag.Errorf("error reading S3 Bucket (%s) Objects metadata: %s", bucket, err)
+		}
+	}
+
+	if err := d.Set("metadata_by_key", metadataByKey); err != nil {
+		return diag.Errorf("error setting metadata_by_key: %s", err)
+	}
+
+	return nil
+}
+
+// headS3BucketObjectsMetadata calls HeadObject for each key, running at most maxConcurrency
+// calls at a time, and returns the user metadata of each object as a JSON object.
+// Keys deleted since they were listed are left out.
+func headS3BucketObjectsMetadata(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket, expectedBucketOwner string,
+	requestPayer *string,
+	keys []string,
+	maxConcurrency int,
+) (map[string]any, error) {
+	metadata := make([]string, len(keys))
+	found := make([]bool, len(keys))
+	errs := make([]error, len(keys))
+	sem := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+
+	for i, key := range keys {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			input := &s3.HeadObjectInput{
+				Bucket:       aws.String(bucket),
+				Key:          aws.String(key),
+				RequestPayer: requestPayer,
+			}
+
+			if expectedBucketOwner != "" {
+				input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+			}
+
+			out, err := headS3Object(ctx, conn, input)
+
+			var deleteMarkerErr *s3DeleteMarkerError
+			if errors.As(err, &deleteMarkerErr) || isAWSErrRequestFailureStatusCode(err, http.StatusNotFound) {
+				log.Printf("[DEBUG] S3 Bucket (%s) Object (%s) deleted since listing", bucket, key)
+
+				return
+			}
+
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", key, err)
+
+				return
+			}
+
+			// AWS Go SDK capitalizes metadata, this is a workaround. https://github.com/aws/aws-sdk-go/issues/445
+			m := make(map[string]string, len(out.Metadata))
+			for k, v := range out.Metadata {
+				m[strings.ToLower(k)] = aws.StringValue(v)
+			}
+
+			b, err := json.Marshal(m)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", key, err)
+
+				return
+			}
+
+			metadata[i] = string(b)
+			found[i] = true
+		})
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	metadataByKey := make(map[string]any, len(keys))
+
+	for i, key := range keys {
+		if found[i] {
+			metadataByKey[key] = metadata[i]
+		}
+	}
+
+	return metadataByKey, nil
+}
+
+type s3ObjectsListing struct {
+	commonPrefixes []string
+	keys           []string
+	owners         []string
+	// folderMarkers holds the keys of zero-byte objects ending in "/".
+	folderMarkers map[string]struct{}
+	// nextContinuationToken is set when the listing stopped before the end of the bucket.
+	nextContinuationToken string
+}
+
+// filterKeys keeps the keys for which keep returns true, along with their owners.
+func (l *s3ObjectsListing) filterKeys(keep func(string) bool) {
+	keys := l.keys[:0]
+	owners := l.owners[:0]
+
+	for i, key := range l.keys {
+		if !keep(key) {
+			continue
+		}
+
+		keys = append(keys, key)
+
+		if i < len(l.owners) {
+			owners = append(owners, l.owners[i])
+		}
+	}
+
+	l.keys = keys
+	l.owners = owners
+}
+
+// listS3BucketObjects pages through ListObjectsV2 until maxKeys keys have been returned,
+// starting from listInput.ContinuationToken when set.
+func listS3BucketObjects(
+	ctx context.Context,
+	conn s3iface.S3API,
+	listInput s3.ListObjectsV2Input,
+	maxKeys int64,
+) (*s3ObjectsListing, error) {
+	// "listInput.MaxKeys" refers to max keys returned in a single request
+	// (i.e., page size), not the total number of keys returned if you page
+	// through the results. "maxKeys" does refer to total keys returned.
+	if maxKeys <= keyRequestPageSize {
+		listInput.MaxKeys = aws.Int64(maxKeys)
+	}
+
+	result := &s3ObjectsListing{folderMarkers: make(map[string]struct{})}
+
 	err := conn.ListObjectsV2PagesWithContext(
 		ctx,
 		&listInput,
 		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
 			for _, commonPrefix := range page.CommonPrefixes {
-				commonPrefixes = append(commonPrefixes, aws.StringValue(commonPrefix.Prefix))
+				result.commonPrefixes = append(result.commonPrefixes, aws.StringValue(commonPrefix.Prefix))
 			}
 
 			for _, object := range page.Contents {
-				keys = append(keys, aws.StringValue(object.Key))
+				key := aws.StringValue(object.Key)
+				result.keys = append(result.keys, key)
 
-				if object.Owner != nil {
-					owners = append(owners, aws.StringValue(object.Owner.ID))
+				if strings.HasSuffix(key, "/") && aws.Int64Value(object.Size) == 0 {
+					result.folderMarkers[key] = struct{}{}
+				}
+
+				// Keep owners aligned with keys by index, even for objects without an owner.
+				if aws.BoolValue(listInput.FetchOwner) {
+					var owner string
+					if object.Owner != nil {
+						owner = aws.StringValue(object.Owner.ID)
+					}
+
+					result.owners = append(result.owners, owner)
 				}
 			}
 
-			maxKeys -= aws.Int64Value(page.KeyCount)
+			// KeyCount includes the common prefixes, only keys count towards max_keys so that
+			// a delimiter listing keeps collecting common prefixes until max_keys keys are returned.
+			maxKeys -= int64(len(page.Contents))
+			result.nextContinuationToken = aws.StringValue(page.NextContinuationToken)
+
+			// Stop on a page boundary so that next_continuation_token resumes right after the last key.
+			if maxKeys <= 0 {
+				return false
+			}
 
 			if maxKeys <= keyRequestPageSize {
 				listInput.MaxKeys = aws.Int64(maxKeys)
@@ -137,20 +455,120 @@ func dataSourceRabataS3BucketObjectsRead(ctx context.Context, d *schema.Resource
 		},
 	)
 	if err != nil {
-		return diag.Errorf("error listing S3 Bucket (%s) Objects: %s", bucket, err)
+		return nil, err
 	}
 
-	if err := d.Set("common_prefixes", commonPrefixes); err != nil {
-		return diag.Errorf("error setting common_prefixes: %s", err)
+	return result, nil
+}
+
+// listS3BucketObjectsByPrefixes lists each prefix concurrently, running at most
+// maxConcurrency listings at a time, and merges the results.
+// maxKeys caps the total number of keys returned across all prefixes.
+func listS3BucketObjectsByPrefixes(
+	ctx context.Context,
+	conn s3iface.S3API,
+	listInput s3.ListObjectsV2Input,
+	prefixes []string,
+	maxKeys int64,
+	maxConcurrency int,
+) (*s3ObjectsListing, error) {
+	slices.Sort(prefixes)
+
+	results := make([]*s3ObjectsListing, len(prefixes))
+	errs := make([]error, len(prefixes))
+	sem := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+
+	for i, prefix := range prefixes {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			input := listInput
+			input.Prefix = aws.String(prefix)
+
+			log.Printf("[DEBUG] Listing S3 Bucket (%s) Objects with prefix: %q", aws.StringValue(input.Bucket), prefix)
+
+			results[i], errs[i] = listS3BucketObjects(ctx, conn, input, maxKeys)
+		})
 	}
 
-	if err := d.Set("keys", keys); err != nil {
-		return diag.Errorf("error setting keys: %s", err)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
 
-	if err := d.Set("owners", owners); err != nil {
-		return diag.Errorf("error setting owners: %s", err)
+	return mergeS3ObjectsListings(results, maxKeys), nil
+}
+
+// mergeS3ObjectsListings merges the listings of several prefixes in lexical key order.
+// Overlapping prefixes list the same keys, which are only returned once.
+// maxKeys caps the number of merged keys.
+func mergeS3ObjectsListings(results []*s3ObjectsListing, maxKeys int64) *s3ObjectsListing {
+	type listedObject struct {
+		key, owner string
 	}
 
-	return nil
+	var (
+		objects        []listedObject
+		commonPrefixes []string
+	)
+
+	merged := &s3ObjectsListing{folderMarkers: make(map[string]struct{})}
+	seen := make(map[string]struct{})
+	withOwners := false
+
+	for _, result := range results {
+		commonPrefixes = append(commonPrefixes, result.commonPrefixes...)
+		maps.Copy(merged.folderMarkers, result.folderMarkers)
+
+		withOwners = withOwners || len(result.owners) > 0
+
+		for i, key := range result.keys {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			object := listedObject{key: key}
+			if i < len(result.owners) {
+				object.owner = result.owners[i]
+			}
+
+			objects = append(objects, object)
+		}
+	}
+
+	slices.SortFunc(objects, func(a, b listedObject) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	if int64(len(objects)) > maxKeys {
+		objects = objects[:maxKeys]
+	}
+
+	for _, object := range objects {
+		merged.keys = append(merged.keys, object.key)
+
+		if withOwners {
+			merged.owners = append(merged.owners, object.owner)
+		}
+	}
+
+	slices.Sort(commonPrefixes)
+	merged.commonPrefixes = slices.Compact(commonPrefixes)
+
+	return merged
+}
+
+// validateGlobPattern validates a path.Match pattern, in which * does not match "/".
+func validateGlobPattern(v any, k string) ([]string, []error) {
+	if _, err := path.Match(v.(string), ""); err != nil { //nolint:forcetypeassert
+		return nil, []error{fmt.Errorf("%q is not a valid glob pattern: %w", k, err)}
+	}
+
+	return nil, nil
 }
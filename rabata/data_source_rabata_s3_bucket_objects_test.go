@@ -0,0 +1,165 @@
+package rabata
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// listObjectsV2PagesS3API serves ListObjectsV2 pages from memory.
+type listObjectsV2PagesS3API struct {
+	s3iface.S3API
+
+	pages []*s3.ListObjectsV2Output
+}
+
+func (c *listObjectsV2PagesS3API) ListObjectsV2PagesWithContext(
+	_ aws.Context,
+	_ *s3.ListObjectsV2Input,
+	fn func(*s3.ListObjectsV2Output, bool) bool,
+	_ ...request.Option,
+) error {
+	for i, page := range c.pages {
+		if !fn(page, i == len(c.pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func TestListS3BucketObjectsMaxKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		pages                  []*s3.ListObjectsV2Output
+		maxKeys                int64
+		expectedKeys           []string
+		expectedCommonPrefixes []string
+		expectedToken          string
+	}{
+		"common prefixes do not count": {
+			pages: []*s3.ListObjectsV2Output{
+				{
+					CommonPrefixes:        []*s3.CommonPrefix{{Prefix: aws.String("a/")}, {Prefix: aws.String("b/")}},
+					Contents:              []*s3.Object{{Key: aws.String("k1")}},
+					KeyCount:              aws.Int64(3),
+					NextContinuationToken: aws.String("t1"),
+				},
+				{
+					CommonPrefixes: []*s3.CommonPrefix{{Prefix: aws.String("c/")}},
+					Contents:       []*s3.Object{{Key: aws.String("k2")}},
+					KeyCount:       aws.Int64(2),
+				},
+			},
+			maxKeys:                2,
+			expectedKeys:           []string{"k1", "k2"},
+			expectedCommonPrefixes: []string{"a/", "b/", "c/"},
+		},
+		"stops on a page boundary": {
+			pages: []*s3.ListObjectsV2Output{
+				{
+					Contents:              []*s3.Object{{Key: aws.String("k1")}, {Key: aws.String("k2")}},
+					KeyCount:              aws.Int64(2),
+					NextContinuationToken: aws.String("t1"),
+				},
+				{
+					Contents: []*s3.Object{{Key: aws.String("k3")}},
+					KeyCount: aws.Int64(1),
+				},
+			},
+			maxKeys:       2,
+			expectedKeys:  []string{"k1", "k2"},
+			expectedToken: "t1",
+		},
+		"only common prefixes": {
+			pages: []*s3.ListObjectsV2Output{
+				{
+					CommonPrefixes:        []*s3.CommonPrefix{{Prefix: aws.String("a/")}},
+					KeyCount:              aws.Int64(1),
+					NextContinuationToken: aws.String("t1"),
+				},
+				{
+					CommonPrefixes: []*s3.CommonPrefix{{Prefix: aws.String("b/")}},
+					KeyCount:       aws.Int64(1),
+				},
+			},
+			maxKeys:                1,
+			expectedCommonPrefixes: []string{"a/", "b/"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := &listObjectsV2PagesS3API{pages: tc.pages}
+
+			got, err := listS3BucketObjects(context.Background(), conn, s3.ListObjectsV2Input{}, tc.maxKeys)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !slices.Equal(got.keys, tc.expectedKeys) {
+				t.Errorf("expected keys %v, got %v", tc.expectedKeys, got.keys)
+			}
+
+			if !slices.Equal(got.commonPrefixes, tc.expectedCommonPrefixes) {
+				t.Errorf("expected common prefixes %v, got %v", tc.expectedCommonPrefixes, got.commonPrefixes)
+			}
+
+			if got.nextContinuationToken != tc.expectedToken {
+				t.Errorf("expected continuation token %q, got %q", tc.expectedToken, got.nextContinuationToken)
+			}
+		})
+	}
+}
+
+func TestListS3BucketObjectsOwners(t *testing.T) {
+	t.Parallel()
+
+	pages := []*s3.ListObjectsV2Output{
+		{
+			Contents: []*s3.Object{
+				{Key: aws.String("a"), Owner: &s3.Owner{ID: aws.String("owner-a")}},
+				{Key: aws.String("b")},
+				{Key: aws.String("c"), Owner: &s3.Owner{ID: aws.String("owner-c")}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		fetchOwner     bool
+		expectedOwners []string
+	}{
+		{name: "fetch owner", fetchOwner: true, expectedOwners: []string{"owner-a", "", "owner-c"}},
+		{name: "no owner", fetchOwner: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := s3.ListObjectsV2Input{FetchOwner: aws.Bool(tc.fetchOwner)}
+
+			got, err := listS3BucketObjects(context.Background(), &listObjectsV2PagesS3API{pages: pages}, input, 1000)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !slices.Equal(got.keys, []string{"a", "b", "c"}) {
+				t.Errorf("expected keys [a b c], got %v", got.keys)
+			}
+
+			if !slices.Equal(got.owners, tc.expectedOwners) {
+				t.Errorf("expected owners %q, got %q", tc.expectedOwners, got.owners)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package rabata
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// s3CapabilityProbes maps each capability attribute to a lightweight read of
+// the corresponding bucket subresource.
+var s3CapabilityProbes = map[string]func(ctx context.Context, conn s3iface.S3API, bucket *string) error{
+	"versioning": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{Bucket: bucket})
+
+		return err
+	},
+	"object_lock": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{Bucket: bucket})
+
+		return err
+	},
+	"lifecycle_configuration": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{
+			Bucket: bucket,
+		})
+
+		return err
+	},
+	"cors": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetBucketCorsWithContext(ctx, &s3.GetBucketCorsInput{Bucket: bucket})
+
+		return err
+	},
+	"policy": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: bucket})
+
+		return err
+	},
+	"encryption": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{Bucket: bucket})
+
+		return err
+	},
+	"tagging": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{Bucket: bucket})
+
+		return err
+	},
+	"website": func(ctx context.Context, conn s3iface.S3API, bucket *string) error {
+		_, err := conn.GetBucketWebsiteWithContext(ctx, &s3.GetBucketWebsiteInput{Bucket: bucket})
+
+		return err
+	},
+}
+
+func dataSourceRabataS3Capabilities() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"bucket": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"probe_timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      5, //nolint:mnd
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+	}
+
+	for name := range s3CapabilityProbes {
+		s[name] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Computed: true,
+		}
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3CapabilitiesRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceRabataS3CapabilitiesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string)                                   //nolint:forcetypeassert
+	timeout := time.Duration(d.Get("probe_timeout").(int)) * time.Second //nolint:forcetypeassert
+
+	_, err := conn.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return diag.Errorf("failed getting S3 bucket: %s Bucket: %q", err, bucket)
+	}
+
+	var (
+		mu           sync.Mutex
+		wg           sync.WaitGroup
+		capabilities = make(map[string]bool, len(s3CapabilityProbes))
+	)
+
+	for name, probe := range s3CapabilityProbes {
+		wg.Go(func() {
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			// Every probe reads a subresource, so a missing configuration
+			// (e.g. NoSuchCORSConfiguration) still means the API is supported.
+			err := probe(probeCtx, conn, aws.String(bucket))
+			supported := !isS3NotImplementedErr(err) && probeCtx.Err() == nil
+
+			log.Printf("[DEBUG] S3 Bucket (%s) capability %s supported: %t (%v)", bucket, name, supported, err)
+
+			mu.Lock()
+			capabilities[name] = supported
+			mu.Unlock()
+		})
+	}
+
+	wg.Wait()
+
+	d.SetId(bucket)
+
+	for name, supported := range capabilities {
+		if err := d.Set(name, supported); err != nil {
+			return diag.Errorf("error setting %s: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+// isS3NotImplementedErr returns true if the endpoint does not implement the requested API.
+func isS3NotImplementedErr(err error) bool {
+	return isAWSErr(err, "NotImplemented", "") || isAWSErrRequestFailureStatusCode(err, http.StatusNotImplemented)
+}
@@ -0,0 +1,81 @@
+package rabata
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRabataS3MultipartUploads() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3MultipartUploadsRead,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"keys": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"upload_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"initiated": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3MultipartUploadsRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	prefix := d.Get("prefix").(string) //nolint:forcetypeassert
+
+	uploads, err := listS3MultipartUploads(ctx, conn, bucket, prefix)
+	if err != nil {
+		return diag.Errorf("error listing S3 Bucket (%s) multipart uploads: %s", bucket, err)
+	}
+
+	// The three lists are aligned by index.
+	keys := make([]string, 0, len(uploads))
+	uploadIDs := make([]string, 0, len(uploads))
+	initiated := make([]string, 0, len(uploads))
+
+	for _, upload := range uploads {
+		keys = append(keys, aws.StringValue(upload.Key))
+		uploadIDs = append(uploadIDs, aws.StringValue(upload.UploadId))
+		initiated = append(initiated, aws.TimeValue(upload.Initiated).Format(time.RFC3339))
+	}
+
+	d.SetId(bucket + "/" + prefix)
+
+	if err := d.Set("keys", keys); err != nil {
+		return diag.Errorf("error setting keys: %s", err)
+	}
+
+	if err := d.Set("upload_ids", uploadIDs); err != nil {
+		return diag.Errorf("error setting upload_ids: %s", err)
+	}
+
+	if err := d.Set("initiated", initiated); err != nil {
+		return diag.Errorf("error setting initiated: %s", err)
+	}
+
+	return nil
+}
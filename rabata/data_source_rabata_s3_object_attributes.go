@@ -0,0 +1,230 @@
+package rabata
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceRabataS3ObjectAttributes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3ObjectAttributesRead,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"version_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"expected_bucket_owner": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(s3.ObjectAttributes_Values(), false),
+				},
+			},
+			"max_parts": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 1000), //nolint:mnd
+			},
+			"checksum": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"checksum_crc32": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"checksum_crc32c": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"checksum_sha1": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"checksum_sha256": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"object_parts": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"is_truncated": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"total_parts_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"parts": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"part_number": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"size": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"object_size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"storage_class": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3ObjectAttributesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	// All attributes are requested by default.
+	attributes := s3.ObjectAttributes_Values()
+	if v, ok := d.GetOk("attributes"); ok {
+		attributes = expandStringSet(v.(*schema.Set)) //nolint:forcetypeassert
+	}
+
+	input := &s3.GetObjectAttributesInput{
+		Bucket:           aws.String(bucket),
+		Key:              aws.String(key),
+		ObjectAttributes: aws.StringSlice(attributes),
+	}
+
+	if v, ok := d.GetOk("version_id"); ok {
+		input.VersionId = aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	if v, ok := d.GetOk("max_parts"); ok {
+		input.MaxParts = aws.Int64(int64(v.(int))) //nolint:forcetypeassert
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	log.Printf("[DEBUG] Reading S3 Bucket Object attributes: %s", input)
+
+	out, err := conn.GetObjectAttributesWithContext(ctx, input)
+	if isS3NotImplementedErr(err) {
+		return diag.Errorf("GetObjectAttributes is not supported by the S3 endpoint: %s", err)
+	}
+
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return diag.Errorf("Failed getting S3 object attributes: %s Bucket: %q Object: %q", err, bucket, key)
+	}
+
+	uniqueID := bucket + "/" + key
+	if out.VersionId != nil {
+		uniqueID += "@" + aws.StringValue(out.VersionId)
+	}
+
+	d.SetId(uniqueID)
+
+	// See https://forums.aws.amazon.com/thread.jspa?threadID=44003
+	d.Set("etag", strings.Trim(aws.StringValue(out.ETag), `"`)) //nolint:errcheck
+	d.Set("object_size", aws.Int64Value(out.ObjectSize))        //nolint:errcheck
+	d.Set("storage_class", out.StorageClass)                    //nolint:errcheck
+	d.Set("version_id", out.VersionId)                          //nolint:errcheck
+
+	if out.LastModified != nil {
+		d.Set("last_modified", out.LastModified.Format(time.RFC1123)) //nolint:errcheck
+	}
+
+	if err := d.Set("checksum", flattenS3Checksum(out.Checksum)); err != nil {
+		return diag.Errorf("error setting checksum: %s", err)
+	}
+
+	if err := d.Set("object_parts", flattenS3ObjectAttributesParts(out.ObjectParts)); err != nil {
+		return diag.Errorf("error setting object_parts: %s", err)
+	}
+
+	return nil
+}
+
+func flattenS3Checksum(checksum *s3.Checksum) []any {
+	if checksum == nil {
+		return nil
+	}
+
+	return []any{map[string]any{
+		"checksum_crc32":  aws.StringValue(checksum.ChecksumCRC32),
+		"checksum_crc32c": aws.StringValue(checksum.ChecksumCRC32C),
+		"checksum_sha1":   aws.StringValue(checksum.ChecksumSHA1),
+		"checksum_sha256": aws.StringValue(checksum.ChecksumSHA256),
+	}}
+}
+
+func flattenS3ObjectAttributesParts(objectParts *s3.GetObjectAttributesParts) []any {
+	if objectParts == nil {
+		return nil
+	}
+
+	parts := make([]any, 0, len(objectParts.Parts))
+	for _, part := range objectParts.Parts {
+		parts = append(parts, map[string]any{
+			"part_number": int(aws.Int64Value(part.PartNumber)),
+			"size":        int(aws.Int64Value(part.Size)),
+		})
+	}
+
+	return []any{map[string]any{
+		"is_truncated":      aws.BoolValue(objectParts.IsTruncated),
+		"total_parts_count": int(aws.Int64Value(objectParts.TotalPartsCount)),
+		"parts":             parts,
+	}}
+}
@@ -0,0 +1,173 @@
+package rabata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/mitchellh/go-homedir"
+)
+
+func dataSourceRabataS3ObjectDownload() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3ObjectDownloadRead,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"destination": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"range": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"version_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"expected_bucket_owner": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"content_length": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"content_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3ObjectDownloadRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string)           //nolint:forcetypeassert
+	key := d.Get("key").(string)                 //nolint:forcetypeassert
+	destination := d.Get("destination").(string) //nolint:forcetypeassert
+
+	path, err := homedir.Expand(destination)
+	if err != nil {
+		return diag.Errorf("Error expanding homedir in destination (%s): %s", destination, err)
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	if v, ok := d.GetOk("range"); ok {
+		input.Range = aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	if v, ok := d.GetOk("version_id"); ok {
+		input.VersionId = aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	log.Printf("[DEBUG] Downloading S3 Bucket Object: %s", input)
+
+	out, err := conn.GetObjectWithContext(ctx, input)
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return diag.Errorf("Failed getting S3 object: %s Bucket: %q Object: %q", err, bucket, key)
+	}
+
+	defer out.Body.Close()
+
+	bytesWritten, err := writeFileAtomically(path, out.Body)
+	if err != nil {
+		return diag.Errorf("Failed writing S3 object (%s/%s) to %s: %s", bucket, key, path, err)
+	}
+
+	log.Printf("[INFO] Saved %d bytes from S3 object %s/%s to %s", bytesWritten, bucket, key, path)
+
+	uniqueID := bucket + "/" + key
+	if out.VersionId != nil {
+		uniqueID += "@" + aws.StringValue(out.VersionId)
+	}
+
+	d.SetId(uniqueID)
+
+	d.Set("content_length", bytesWritten)  //nolint:errcheck
+	d.Set("content_type", out.ContentType) //nolint:errcheck
+	// See https://forums.aws.amazon.com/thread.jspa?threadID=44003
+	d.Set("etag", strings.Trim(aws.StringValue(out.ETag), `"`)) //nolint:errcheck
+	d.Set("version_id", out.VersionId)                          //nolint:errcheck
+
+	return nil
+}
+
+// writeFileAtomically streams r into a temporary file next to path and renames it
+// over path once complete, so that a failed download never leaves a partial file.
+func writeFileAtomically(path string, r io.Reader) (int64, error) {
+	dir := filepath.Dir(path)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:mnd
+		return 0, err
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		// Nothing to remove after a successful rename.
+		if err := os.Remove(file.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[WARN] Error removing temporary file (%s): %s", file.Name(), err)
+		}
+	}()
+
+	n, err := io.Copy(file, r)
+	if err != nil {
+		file.Close()
+
+		return 0, err
+	}
+
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := os.Chmod(file.Name(), 0o644); err != nil { //nolint:mnd
+		return 0, err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return 0, fmt.Errorf("error renaming %s: %w", file.Name(), err)
+	}
+
+	return n, nil
+}
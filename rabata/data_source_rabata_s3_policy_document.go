@@ -0,0 +1,253 @@
+package rabata
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rabataio/terraform-provider-rabata/rabata/internal/hashcode"
+)
+
+const s3PolicyDocumentVersion = "2012-10-17"
+
+var (
+	s3PolicyActionRegexp   = regexp.MustCompile(`^(\*|s3:[A-Za-z*]+)$`)
+	s3PolicyResourceRegexp = regexp.MustCompile(`^(\*|arn:[a-z][a-z0-9-]*:s3:::.+)$`)
+)
+
+// s3PolicyDocument is the JSON representation of an S3 bucket policy.
+// Field order and sorted values keep the generated JSON stable between reads.
+type s3PolicyDocument struct {
+	Version    string               `json:"Version,omitempty"`
+	ID         string               `json:"Id,omitempty"`
+	Statements []*s3PolicyStatement `json:"Statement"`
+}
+
+type s3PolicyStatement struct {
+	Sid           string                    `json:"Sid,omitempty"`
+	Effect        string                    `json:"Effect"`
+	Principals    any                       `json:"Principal,omitempty"`
+	NotPrincipals any                       `json:"NotPrincipal,omitempty"`
+	Actions       any                       `json:"Action,omitempty"`
+	NotActions    any                       `json:"NotAction,omitempty"`
+	Resources     any                       `json:"Resource,omitempty"`
+	NotResources  any                       `json:"NotResource,omitempty"`
+	Conditions    map[string]map[string]any `json:"Condition,omitempty"`
+}
+
+func dataSourceRabataS3PolicyDocument() *schema.Resource {
+	actionsSchema := &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringMatch(s3PolicyActionRegexp, "must be \"*\" or an S3 action, e.g. s3:GetObject"),
+		},
+	}
+
+	resourcesSchema := &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringMatch(s3PolicyResourceRegexp, "must be \"*\" or an S3 ARN (arn:PARTITION:s3:::...)"),
+		},
+	}
+
+	principalsSchema := &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"identifiers": {
+					Type:     schema.TypeSet,
+					Required: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3PolicyDocumentRead,
+
+		Schema: map[string]*schema.Schema{
+			"policy_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      s3PolicyDocumentVersion,
+				ValidateFunc: validation.StringInSlice([]string{"2008-10-17", s3PolicyDocumentVersion}, false),
+			},
+			"statement": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"sid": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"effect": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "Allow",
+							ValidateFunc: validation.StringInSlice([]string{"Allow", "Deny"}, false),
+						},
+						"actions":        actionsSchema,
+						"not_actions":    actionsSchema,
+						"resources":      resourcesSchema,
+						"not_resources":  resourcesSchema,
+						"principals":     principalsSchema,
+						"not_principals": principalsSchema,
+						"condition": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"test": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"variable": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"values": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3PolicyDocumentRead(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	doc := &s3PolicyDocument{
+		Version: d.Get("version").(string),   //nolint:forcetypeassert
+		ID:      d.Get("policy_id").(string), //nolint:forcetypeassert
+	}
+
+	statements := d.Get("statement").([]any) //nolint:forcetypeassert
+	doc.Statements = make([]*s3PolicyStatement, 0, len(statements))
+
+	for i, v := range statements {
+		tfStatement := v.(map[string]any) //nolint:forcetypeassert
+
+		statement := &s3PolicyStatement{
+			Sid:           tfStatement["sid"].(string),                                           //nolint:forcetypeassert
+			Effect:        tfStatement["effect"].(string),                                        //nolint:forcetypeassert
+			Actions:       expandS3PolicyValues(tfStatement["actions"].(*schema.Set)),            //nolint:forcetypeassert
+			NotActions:    expandS3PolicyValues(tfStatement["not_actions"].(*schema.Set)),        //nolint:forcetypeassert
+			Resources:     expandS3PolicyValues(tfStatement["resources"].(*schema.Set)),          //nolint:forcetypeassert
+			NotResources:  expandS3PolicyValues(tfStatement["not_resources"].(*schema.Set)),      //nolint:forcetypeassert
+			Principals:    expandS3PolicyPrincipals(tfStatement["principals"].(*schema.Set)),     //nolint:forcetypeassert
+			NotPrincipals: expandS3PolicyPrincipals(tfStatement["not_principals"].(*schema.Set)), //nolint:forcetypeassert
+			Conditions:    expandS3PolicyConditions(tfStatement["condition"].(*schema.Set)),      //nolint:forcetypeassert
+		}
+
+		if statement.Actions == nil && statement.NotActions == nil {
+			return diag.Errorf("statement %d: one of actions or not_actions must be set", i)
+		}
+
+		if statement.Resources == nil && statement.NotResources == nil {
+			return diag.Errorf("statement %d: one of resources or not_resources must be set", i)
+		}
+
+		doc.Statements = append(doc.Statements, statement)
+	}
+
+	jsonDoc, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return diag.Errorf("error marshaling S3 policy document: %s", err)
+	}
+
+	d.SetId(strconv.Itoa(hashcode.String(string(jsonDoc))))
+	d.Set("json", string(jsonDoc)) //nolint:errcheck
+
+	return nil
+}
+
+// expandS3PolicyValues returns the sorted set values, collapsing a single
+// value to a plain string as the S3 API does when returning policies.
+func expandS3PolicyValues(set *schema.Set) any {
+	values := expandStringSet(set)
+
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	}
+
+	slices.Sort(values)
+
+	return values
+}
+
+func expandS3PolicyPrincipals(set *schema.Set) any {
+	if set.Len() == 0 {
+		return nil
+	}
+
+	principals := make(map[string]any, set.Len())
+
+	for _, v := range set.List() {
+		tfPrincipal := v.(map[string]any)                                             //nolint:forcetypeassert
+		principalType := tfPrincipal["type"].(string)                                 //nolint:forcetypeassert
+		identifiers := expandS3PolicyValues(tfPrincipal["identifiers"].(*schema.Set)) //nolint:forcetypeassert
+
+		// "Principal": "*" grants access to everyone, including anonymous users.
+		if principalType == "*" && identifiers == "*" {
+			return "*"
+		}
+
+		principals[principalType] = identifiers
+	}
+
+	return principals
+}
+
+func expandS3PolicyConditions(set *schema.Set) map[string]map[string]any {
+	if set.Len() == 0 {
+		return nil
+	}
+
+	conditions := make(map[string]map[string]any)
+
+	for _, v := range set.List() {
+		tfCondition := v.(map[string]any)            //nolint:forcetypeassert
+		test := tfCondition["test"].(string)         //nolint:forcetypeassert
+		variable := tfCondition["variable"].(string) //nolint:forcetypeassert
+
+		if conditions[test] == nil {
+			conditions[test] = make(map[string]any)
+		}
+
+		conditions[test][variable] = expandS3PolicyValues(tfCondition["values"].(*schema.Set)) //nolint:forcetypeassert
+	}
+
+	return conditions
+}
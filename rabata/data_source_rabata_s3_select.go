@@ -0,0 +1,343 @@
+package rabata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceRabataS3Select() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRabataS3SelectRead,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"expression": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"expression_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      s3.ExpressionTypeSql,
+				ValidateFunc: validation.StringInSlice(s3.ExpressionType_Values(), false),
+			},
+			"input_serialization": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"compression_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      s3.CompressionTypeNone,
+							ValidateFunc: validation.StringInSlice(s3.CompressionType_Values(), false),
+						},
+						"csv": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"allow_quoted_record_delimiter": {
+										Type:     schema.TypeBool,
+										Optional: true,
+									},
+									"comments": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"field_delimiter": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"file_header_info": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validation.StringInSlice(s3.FileHeaderInfo_Values(), false),
+									},
+									"quote_character": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"quote_escape_character": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"record_delimiter": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"json": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": {
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice(s3.JSONType_Values(), false),
+									},
+								},
+							},
+						},
+						"parquet": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"output_serialization": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"csv": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"field_delimiter": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"quote_character": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"quote_escape_character": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"quote_fields": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validation.StringInSlice(s3.QuoteFields_Values(), false),
+									},
+									"record_delimiter": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"json": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"record_delimiter": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"max_result_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultS3ObjectMaxBodySize,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"expected_bucket_owner": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"result": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRabataS3SelectRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	inputSerialization, err := expandS3SelectInputSerialization(
+		d.Get("input_serialization").([]any)) //nolint:forcetypeassert
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	outputSerialization, err := expandS3SelectOutputSerialization(
+		d.Get("output_serialization").([]any)) //nolint:forcetypeassert
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &s3.SelectObjectContentInput{
+		Bucket:              aws.String(bucket),
+		Key:                 aws.String(key),
+		Expression:          aws.String(d.Get("expression").(string)),      //nolint:forcetypeassert
+		ExpressionType:      aws.String(d.Get("expression_type").(string)), //nolint:forcetypeassert
+		InputSerialization:  inputSerialization,
+		OutputSerialization: outputSerialization,
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	log.Printf("[DEBUG] Selecting S3 Bucket (%s) Object (%s) content: %s", bucket, key, input)
+
+	out, err := conn.SelectObjectContentWithContext(ctx, input)
+	if isS3NotImplementedErr(err) {
+		return diag.Errorf("S3 Select is not supported by the S3 endpoint: %s", err)
+	}
+
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return diag.Errorf("error selecting S3 Bucket (%s) Object (%s) content: %s", bucket, key, err)
+	}
+
+	defer out.EventStream.Close()
+
+	maxResultSize := d.Get("max_result_size").(int) //nolint:forcetypeassert
+
+	var result bytes.Buffer
+
+	for event := range out.EventStream.Events() {
+		records, ok := event.(*s3.RecordsEvent)
+		if !ok {
+			continue
+		}
+
+		if result.Len()+len(records.Payload) > maxResultSize {
+			return diag.Errorf("S3 Bucket (%s) Object (%s) select result is larger than max_result_size (%d bytes)",
+				bucket, key, maxResultSize)
+		}
+
+		result.Write(records.Payload)
+	}
+
+	if err := out.EventStream.Err(); err != nil {
+		return diag.Errorf("error reading S3 Bucket (%s) Object (%s) select result: %s", bucket, key, err)
+	}
+
+	d.SetId(bucket + "/" + key)
+	d.Set("result", result.String()) //nolint:errcheck
+
+	return nil
+}
+
+func expandS3SelectInputSerialization(l []any) (*s3.InputSerialization, error) {
+	tfMap := l[0].(map[string]any) //nolint:forcetypeassert
+
+	serialization := &s3.InputSerialization{
+		CompressionType: aws.String(tfMap["compression_type"].(string)), //nolint:forcetypeassert
+	}
+
+	formats := 0
+
+	if v, ok := tfMap["csv"].([]any); ok && len(v) > 0 {
+		formats++
+
+		// An empty csv block uses the default delimiters.
+		csv, _ := v[0].(map[string]any)
+		allowQuotedRecordDelimiter, _ := csv["allow_quoted_record_delimiter"].(bool)
+		serialization.CSV = &s3.CSVInput{
+			AllowQuotedRecordDelimiter: aws.Bool(allowQuotedRecordDelimiter),
+			Comments:                   s3SelectOptionalString(csv, "comments"),
+			FieldDelimiter:             s3SelectOptionalString(csv, "field_delimiter"),
+			FileHeaderInfo:             s3SelectOptionalString(csv, "file_header_info"),
+			QuoteCharacter:             s3SelectOptionalString(csv, "quote_character"),
+			QuoteEscapeCharacter:       s3SelectOptionalString(csv, "quote_escape_character"),
+			RecordDelimiter:            s3SelectOptionalString(csv, "record_delimiter"),
+		}
+	}
+
+	if v, ok := tfMap["json"].([]any); ok && len(v) > 0 {
+		formats++
+
+		json, _ := v[0].(map[string]any)
+		serialization.JSON = &s3.JSONInput{
+			Type: aws.String(json["type"].(string)), //nolint:forcetypeassert
+		}
+	}
+
+	if v, ok := tfMap["parquet"].(bool); ok && v {
+		formats++
+
+		serialization.Parquet = &s3.ParquetInput{}
+	}
+
+	if formats != 1 {
+		return nil, errors.New("input_serialization: exactly one of csv, json or parquet must be set")
+	}
+
+	return serialization, nil
+}
+
+func expandS3SelectOutputSerialization(l []any) (*s3.OutputSerialization, error) {
+	tfMap := l[0].(map[string]any) //nolint:forcetypeassert
+
+	serialization := &s3.OutputSerialization{}
+
+	csvList, _ := tfMap["csv"].([]any)
+	jsonList, _ := tfMap["json"].([]any)
+
+	if (len(csvList) > 0) == (len(jsonList) > 0) {
+		return nil, errors.New("output_serialization: exactly one of csv or json must be set")
+	}
+
+	if len(csvList) > 0 {
+		// An empty csv block uses the default delimiters.
+		csv, _ := csvList[0].(map[string]any)
+		serialization.CSV = &s3.CSVOutput{
+			FieldDelimiter:       s3SelectOptionalString(csv, "field_delimiter"),
+			QuoteCharacter:       s3SelectOptionalString(csv, "quote_character"),
+			QuoteEscapeCharacter: s3SelectOptionalString(csv, "quote_escape_character"),
+			QuoteFields:          s3SelectOptionalString(csv, "quote_fields"),
+			RecordDelimiter:      s3SelectOptionalString(csv, "record_delimiter"),
+		}
+	} else {
+		json, _ := jsonList[0].(map[string]any)
+		serialization.JSON = &s3.JSONOutput{
+			RecordDelimiter: s3SelectOptionalString(json, "record_delimiter"),
+		}
+	}
+
+	return serialization, nil
+}
+
+// s3SelectOptionalString returns nil for unset attributes, so that S3 applies its defaults.
+func s3SelectOptionalString(m map[string]any, k string) *string {
+	if v, ok := m[k].(string); ok && v != "" {
+		return aws.String(v)
+	}
+
+	return nil
+}
@@ -0,0 +1,431 @@
+package rabata
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// fakeS3LastModified is the modification time of every object stored by fakeS3.
+var fakeS3LastModified = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeS3Object is an object stored by fakeS3.
+type fakeS3Object struct {
+	body            []byte
+	contentType     string
+	contentEncoding string
+	metadata        map[string]string
+	tags            map[string]string
+	legalHold       string
+	versionID       string
+}
+
+// fakeS3 is an in-memory S3 API of a single versioned bucket, recording the operations it serves.
+type fakeS3 struct {
+	s3iface.S3API
+
+	mu       sync.Mutex
+	objects  map[string]*fakeS3Object
+	versions int
+	calls    []string
+	// systemMetadata is returned by HeadObject on top of the user metadata of every object.
+	systemMetadata map[string]string
+	// dropMetadataOnCopy makes CopyObject with the COPY directives lose the metadata and tags,
+	// as some backends do.
+	dropMetadataOnCopy bool
+	// corruptBodies makes GetObject flip the first byte of the bodies it returns.
+	corruptBodies bool
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: make(map[string]*fakeS3Object)}
+}
+
+func (c *fakeS3) record(operation string) {
+	c.calls = append(c.calls, operation)
+}
+
+func (c *fakeS3) nextVersionID() string {
+	c.versions++
+
+	return fmt.Sprintf("v%d", c.versions)
+}
+
+func (c *fakeS3) object(key string) (*fakeS3Object, error) {
+	object, ok := c.objects[key]
+	if !ok {
+		return nil, awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), http.StatusNotFound, "")
+	}
+
+	return object, nil
+}
+
+// Calls returns the operations served so far and resets the record.
+func (c *fakeS3) Calls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	calls := c.calls
+	c.calls = nil
+
+	return calls
+}
+
+func (c *fakeS3) PutObjectWithContext(
+	_ aws.Context,
+	input *s3.PutObjectInput,
+	_ ...request.Option,
+) (*s3.PutObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("PutObject")
+
+	var body []byte
+
+	if input.Body != nil {
+		var err error
+		if body, err = io.ReadAll(input.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	object := &fakeS3Object{
+		body:            body,
+		contentType:     aws.StringValue(input.ContentType),
+		contentEncoding: aws.StringValue(input.ContentEncoding),
+		metadata:        fakeS3Metadata(input.Metadata),
+		tags:            make(map[string]string),
+		legalHold:       aws.StringValue(input.ObjectLockLegalHoldStatus),
+		versionID:       c.nextVersionID(),
+	}
+	c.objects[aws.StringValue(input.Key)] = object
+
+	return &s3.PutObjectOutput{ETag: aws.String(object.etag()), VersionId: aws.String(object.versionID)}, nil
+}
+
+func (c *fakeS3) HeadObjectWithContext(
+	_ aws.Context,
+	input *s3.HeadObjectInput,
+	_ ...request.Option,
+) (*s3.HeadObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("HeadObject")
+
+	object, err := c.object(aws.StringValue(input.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	if ifNoneMatch := aws.StringValue(input.IfNoneMatch); ifNoneMatch != "" && strings.Trim(ifNoneMatch, `"`) ==
+		strings.Trim(object.etag(), `"`) {
+		return nil, awserr.NewRequestFailure(awserr.New("NotModified", "Not Modified", nil), http.StatusNotModified, "")
+	}
+
+	// The SDK capitalizes the metadata keys of responses.
+	metadata := make(map[string]*string)
+	for k, v := range object.metadata {
+		metadata[strings.ToUpper(k[:1])+k[1:]] = aws.String(v)
+	}
+
+	for k, v := range c.systemMetadata {
+		metadata[k] = aws.String(v)
+	}
+
+	out := &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(object.body))),
+		ETag:          aws.String(object.etag()),
+		LastModified:  aws.Time(fakeS3LastModified),
+		Metadata:      metadata,
+		VersionId:     aws.String(object.versionID),
+	}
+
+	if object.contentType != "" {
+		out.ContentType = aws.String(object.contentType)
+	}
+
+	if object.contentEncoding != "" {
+		out.ContentEncoding = aws.String(object.contentEncoding)
+	}
+
+	if object.legalHold != "" {
+		out.ObjectLockLegalHoldStatus = aws.String(object.legalHold)
+	}
+
+	return out, nil
+}
+
+// HeadObjectRequest serves the request with HeadObjectWithContext when it is sent.
+func (c *fakeS3) HeadObjectRequest(input *s3.HeadObjectInput) (*request.Request, *s3.HeadObjectOutput) {
+	out := &s3.HeadObjectOutput{}
+	req := request.New(aws.Config{}, metadata.ClientInfo{}, request.Handlers{}, nil,
+		&request.Operation{Name: "HeadObject"}, input, out)
+
+	req.Handlers.Send.PushBack(func(r *request.Request) {
+		o, err := c.HeadObjectWithContext(r.Context(), input)
+		if err != nil {
+			r.Error = err
+
+			return
+		}
+
+		*out = *o
+	})
+
+	return req, out
+}
+
+func (c *fakeS3) GetObjectWithContext(
+	_ aws.Context,
+	input *s3.GetObjectInput,
+	_ ...request.Option,
+) (*s3.GetObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("GetObject")
+
+	object, err := c.object(aws.StringValue(input.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	body := bytes.Clone(object.body)
+	if c.corruptBodies && len(body) > 0 {
+		body[0] ^= 0xff
+	}
+
+	return &s3.GetObjectOutput{
+		Body:            io.NopCloser(bytes.NewReader(body)),
+		ContentEncoding: aws.String(object.contentEncoding),
+		ContentLength:   aws.Int64(int64(len(body))),
+		ContentType:     aws.String(object.contentType),
+		ETag:            aws.String(object.etag()),
+		LastModified:    aws.Time(fakeS3LastModified),
+		Metadata:        aws.StringMap(object.metadata),
+		VersionId:       aws.String(object.versionID),
+	}, nil
+}
+
+func (c *fakeS3) CopyObjectWithContext(
+	_ aws.Context,
+	input *s3.CopyObjectInput,
+	_ ...request.Option,
+) (*s3.CopyObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("CopyObject")
+
+	_, sourceKey, _ := strings.Cut(aws.StringValue(input.CopySource), "/")
+
+	sourceKey, err := url.PathUnescape(sourceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := c.object(sourceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	object := &fakeS3Object{
+		body:            source.body,
+		contentType:     source.contentType,
+		contentEncoding: source.contentEncoding,
+		metadata:        maps.Clone(source.metadata),
+		tags:            maps.Clone(source.tags),
+		versionID:       c.nextVersionID(),
+	}
+
+	if c.dropMetadataOnCopy {
+		object.metadata = make(map[string]string)
+		object.tags = make(map[string]string)
+	}
+
+	if aws.StringValue(input.MetadataDirective) == s3.MetadataDirectiveReplace {
+		object.contentType = aws.StringValue(input.ContentType)
+		object.contentEncoding = aws.StringValue(input.ContentEncoding)
+		object.metadata = fakeS3Metadata(input.Metadata)
+	}
+
+	if aws.StringValue(input.TaggingDirective) == s3.TaggingDirectiveReplace {
+		tags, err := url.ParseQuery(aws.StringValue(input.Tagging))
+		if err != nil {
+			return nil, err
+		}
+
+		object.tags = make(map[string]string)
+		for k := range tags {
+			object.tags[k] = tags.Get(k)
+		}
+	}
+
+	c.objects[aws.StringValue(input.Key)] = object
+
+	return &s3.CopyObjectOutput{
+		CopyObjectResult: &s3.CopyObjectResult{ETag: aws.String(object.etag())},
+		VersionId:        aws.String(object.versionID),
+	}, nil
+}
+
+func (c *fakeS3) DeleteObjectWithContext(
+	_ aws.Context,
+	input *s3.DeleteObjectInput,
+	_ ...request.Option,
+) (*s3.DeleteObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("DeleteObject")
+	delete(c.objects, aws.StringValue(input.Key))
+
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (c *fakeS3) PutObjectAclWithContext(
+	_ aws.Context,
+	input *s3.PutObjectAclInput,
+	_ ...request.Option,
+) (*s3.PutObjectAclOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("PutObjectAcl")
+
+	if _, err := c.object(aws.StringValue(input.Key)); err != nil {
+		return nil, err
+	}
+
+	return &s3.PutObjectAclOutput{}, nil
+}
+
+func (c *fakeS3) PutObjectLegalHoldWithContext(
+	_ aws.Context,
+	input *s3.PutObjectLegalHoldInput,
+	_ ...request.Option,
+) (*s3.PutObjectLegalHoldOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("PutObjectLegalHold")
+
+	object, err := c.object(aws.StringValue(input.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	object.legalHold = aws.StringValue(input.LegalHold.Status)
+
+	return &s3.PutObjectLegalHoldOutput{}, nil
+}
+
+func (c *fakeS3) GetObjectTaggingWithContext(
+	_ aws.Context,
+	input *s3.GetObjectTaggingInput,
+	_ ...request.Option,
+) (*s3.GetObjectTaggingOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("GetObjectTagging")
+
+	object, err := c.object(aws.StringValue(input.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &s3.GetObjectTaggingOutput{TagSet: []*s3.Tag{}}
+	for k, v := range object.tags {
+		out.TagSet = append(out.TagSet, &s3.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	return out, nil
+}
+
+func (c *fakeS3) PutObjectTaggingWithContext(
+	_ aws.Context,
+	input *s3.PutObjectTaggingInput,
+	_ ...request.Option,
+) (*s3.PutObjectTaggingOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("PutObjectTagging")
+
+	object, err := c.object(aws.StringValue(input.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	object.tags = make(map[string]string)
+	for _, tag := range input.Tagging.TagSet {
+		object.tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+
+func (o *fakeS3Object) etag() string {
+	sum := md5.Sum(o.body) //nolint:gosec
+
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// fakeS3Metadata returns the metadata of a request with lowercase keys, as S3 stores them.
+func fakeS3Metadata(metadata map[string]*string) map[string]string {
+	m := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		m[strings.ToLower(k)] = aws.StringValue(v)
+	}
+
+	return m
+}
+
+// applyS3Resource plans config against state like terraform apply does and applies the plan,
+// failing the test on errors. A nil state creates the resource.
+func applyS3Resource(
+	t *testing.T,
+	r *schema.Resource,
+	meta any,
+	state *terraform.InstanceState,
+	config map[string]any,
+) *terraform.InstanceState {
+	t.Helper()
+
+	diff, err := r.Diff(t.Context(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatalf("error planning: %s", err)
+	}
+
+	if diff == nil {
+		return state
+	}
+
+	newState, diags := r.Apply(t.Context(), state, diff, meta)
+	if diags.HasError() {
+		t.Fatalf("error applying: %v", diags)
+	}
+
+	return newState
+}
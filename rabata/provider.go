@@ -2,9 +2,18 @@ package rabata
 
 import (
 	"context"
-
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // Provider returns a *schema.Provider.
@@ -69,20 +78,94 @@ func Provider() *schema.Provider {
 			"s3_force_path_style": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     true,
+				DefaultFunc: envBoolDefaultFunc("RABATA_S3_FORCE_PATH_STYLE", true),
 				Description: descriptions["s3_force_path_style"],
 			},
+
+			"anonymous": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"access_key", "secret_key", "profile", "shared_credentials_file"},
+				Description:   descriptions["anonymous"],
+			},
+
+			"log_upload_progress": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["log_upload_progress"],
+			},
+
+			"multipart_threshold": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  descriptions["multipart_threshold"],
+			},
+
+			"multipart_part_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(s3manager.DefaultUploadPartSize),
+				ValidateFunc: validation.IntAtLeast(int(s3manager.MinUploadPartSize)),
+				Description:  descriptions["multipart_part_size"],
+			},
+
+			"signing_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "s3",
+				ValidateFunc: validation.NoZeroValues,
+				Description:  descriptions["signing_name"],
+			},
+
+			"partition": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "aws",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[a-z][a-z0-9-]*$`),
+					"must contain only lowercase alphanumeric characters and hyphens"),
+				Description: descriptions["partition"],
+			},
+
+			"checksum_validation": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  checksumValidationWhenRequired,
+				ValidateFunc: validation.StringInSlice([]string{
+					checksumValidationWhenSupported,
+					checksumValidationWhenRequired,
+					checksumValidationDisabled,
+				}, false),
+				Description: descriptions["checksum_validation"],
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"rabata_s3_bucket":         dataSourceRabataS3Bucket(),
-			"rabata_s3_bucket_object":  dataSourceRabataS3BucketObject(),
-			"rabata_s3_bucket_objects": dataSourceRabataS3BucketObjects(),
+			"rabata_s3_bucket":            dataSourceRabataS3Bucket(),
+			"rabata_s3_bucket_accelerate": dataSourceRabataS3BucketAccelerate(),
+			"rabata_s3_bucket_details":    dataSourceRabataS3BucketDetails(),
+			"rabata_s3_bucket_metric":     dataSourceRabataS3BucketMetric(),
+			"rabata_s3_bucket_object":     dataSourceRabataS3BucketObject(),
+			"rabata_s3_bucket_objects":    dataSourceRabataS3BucketObjects(),
+			"rabata_s3_capabilities":      dataSourceRabataS3Capabilities(),
+			"rabata_s3_multipart_uploads": dataSourceRabataS3MultipartUploads(),
+			"rabata_s3_object_attributes": dataSourceRabataS3ObjectAttributes(),
+			"rabata_s3_object_download":   dataSourceRabataS3ObjectDownload(),
+			"rabata_s3_policy_document":   dataSourceRabataS3PolicyDocument(),
+			"rabata_s3_select":            dataSourceRabataS3Select(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"rabata_s3_bucket":        resourceRabataS3Bucket(),
-			"rabata_s3_bucket_object": resourceRabataS3BucketObject(),
+			"rabata_s3_bucket":                   resourceRabataS3Bucket(),
+			"rabata_s3_bucket_access":            resourceRabataS3BucketAccess(),
+			"rabata_s3_bucket_lifecycle_rule":    resourceRabataS3BucketLifecycleRule(),
+			"rabata_s3_bucket_multipart_cleanup": resourceRabataS3BucketMultipartCleanup(),
+			"rabata_s3_bucket_object":            resourceRabataS3BucketObject(),
+			"rabata_s3_bucket_object_from_url":   resourceRabataS3BucketObjectFromURL(),
+			"rabata_s3_bucket_object_move":       resourceRabataS3BucketObjectMove(),
 		},
 	}
 
@@ -132,7 +215,32 @@ func init() {
 		"s3_force_path_style": "Set this to true to force the request to use path-style addressing,\n" +
 			"i.e., http://s3.eu-west-1.rabata.io/BUCKET/KEY. By default, the S3 client will\n" +
 			"use virtual hosted bucket addressing when possible\n" +
-			"(http://BUCKET.s3.eu-west-1.rabata.io/KEY). Specific to the S3 service.",
+			"(http://BUCKET.s3.eu-west-1.rabata.io/KEY). Specific to the S3 service.\n" +
+			"Required for bucket names containing dots over TLS.\n" +
+			"Can also be set with the RABATA_S3_FORCE_PATH_STYLE environment variable.",
+
+		"anonymous": "Set this to true to send unsigned requests without credentials.\n" +
+			"Only data sources reading public buckets and objects can be used in this mode.",
+
+		"log_upload_progress": "Set this to true to log the progress of object uploads from a `source` file\n" +
+			"at INFO level.",
+
+		"multipart_threshold": "Objects of at least this many bytes are uploaded with a multipart upload.\n" +
+			"Multipart objects have an ETag that is not the MD5 of their content, so `etag`\n" +
+			"should not be set on them. Defaults to 0, which disables multipart uploads.",
+
+		"multipart_part_size": "The size in bytes of each part of a multipart upload. Must be at least\n" +
+			"5 MiB (5242880), which is also the default.",
+
+		"signing_name": "The service name used to sign requests with AWS Signature Version 4.\n" +
+			"Only needs to be changed for gateways expecting a service other than `s3`.",
+
+		"checksum_validation": "When to compute and validate object checksums. With `when_required`, the default,\n" +
+			"object uploads are sent with a Content-MD5 header. `when_supported` also checks the body read by the\n" +
+			"`rabata_s3_bucket_object` data source against the object ETag when it is an MD5.\n" +
+			"`disabled` only sends a checksum with the requests that require one.",
+
+		"partition": "The partition used in the ARNs computed by the provider, e.g. arn:PARTITION:s3:::BUCKET.",
 	}
 
 	endpointServiceNames = []string{
@@ -140,42 +248,116 @@ func init() {
 	}
 }
 
+const (
+	checksumValidationWhenSupported = "when_supported"
+	checksumValidationWhenRequired  = "when_required"
+	checksumValidationDisabled      = "disabled"
+)
+
+// regionDNSSuffixes lists the regions whose DNS suffix does not follow
+// the REGION.rabata.io pattern.
+var regionDNSSuffixes = map[string]string{
+	"stage": "stage.rabata.io",
+}
+
+// getDNSSuffix returns the DNS suffix of the Rabata endpoints for the region.
+// The RABATA_ENDPOINT environment variable overrides it for custom domains.
 func getDNSSuffix(region string) string {
+	return resolveDNSSuffix(region, os.Getenv("RABATA_ENDPOINT"))
+}
+
+// resolveDNSSuffix returns customDomain if it is set, or the DNS suffix of the Rabata endpoints for the region.
+func resolveDNSSuffix(region, customDomain string) string {
+	if v := strings.TrimSpace(customDomain); v != "" {
+		return strings.TrimSuffix(v, ".")
+	}
+
 	if region == "" {
 		region = "eu-west-1"
 	}
 
+	if suffix, ok := regionDNSSuffixes[region]; ok {
+		return suffix
+	}
+
 	return region + ".rabata.io"
 }
 
+// envBoolDefaultFunc is like schema.EnvDefaultFunc for boolean attributes.
+// It accepts the values understood by strconv.ParseBool as well as yes/no and on/off.
+func envBoolDefaultFunc(k string, dv bool) schema.SchemaDefaultFunc {
+	return func() (any, error) {
+		v := strings.ToLower(strings.TrimSpace(os.Getenv(k)))
+
+		switch v {
+		case "":
+			return dv, nil
+		case "yes", "on":
+			return true, nil
+		case "no", "off":
+			return false, nil
+		}
+
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean value %q in %s environment variable", v, k)
+		}
+
+		return b, nil
+	}
+}
+
+// normalizeRegion trims the region and rejects an empty value,
+// which would otherwise produce a malformed ".rabata.io" endpoint host.
+func normalizeRegion(region string) (string, error) {
+	region = strings.ToLower(strings.TrimSpace(region))
+	if region == "" {
+		return "", errors.New("region must not be empty: set the provider region argument or RABATA_REGION")
+	}
+
+	return region, nil
+}
+
 func providerConfigure(d *schema.ResourceData, terraformVersion string) (any, diag.Diagnostics) {
-	region := d.Get("region").(string) //nolint:forcetypeassert
+	region, err := normalizeRegion(d.Get("region").(string)) //nolint:forcetypeassert
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
 
 	//nolint:forcetypeassert
 	config := Config{
-		AccessKey:     d.Get("access_key").(string),
-		SecretKey:     d.Get("secret_key").(string),
-		Profile:       d.Get("profile").(string),
-		Region:        region,
-		CredsFilename: d.Get("shared_credentials_file").(string),
-		Endpoints: map[string]string{
-			"s3": "https://s3." + getDNSSuffix(region),
-		},
-		MaxRetries:       d.Get("max_retries").(int),
-		Insecure:         d.Get("insecure").(bool),
-		S3ForcePathStyle: d.Get("s3_force_path_style").(bool),
-		terraformVersion: terraformVersion,
+		AccessKey:          d.Get("access_key").(string),
+		SecretKey:          d.Get("secret_key").(string),
+		Profile:            d.Get("profile").(string),
+		Region:             region,
+		CredsFilename:      d.Get("shared_credentials_file").(string),
+		MaxRetries:         d.Get("max_retries").(int),
+		Insecure:           d.Get("insecure").(bool),
+		S3ForcePathStyle:   d.Get("s3_force_path_style").(bool),
+		Anonymous:          d.Get("anonymous").(bool),
+		LogUploadProgress:  d.Get("log_upload_progress").(bool),
+		MultipartThreshold: int64(d.Get("multipart_threshold").(int)),
+		MultipartPartSize:  int64(d.Get("multipart_part_size").(int)),
+		SigningName:        d.Get("signing_name").(string),
+		Partition:          d.Get("partition").(string),
+		ChecksumValidation: d.Get("checksum_validation").(string),
+		terraformVersion:   terraformVersion,
 	}
 
-	endpointsSet := d.Get("endpoints").(*schema.Set) //nolint:forcetypeassert
+	overrides := make(map[string]string)
 
-	for _, endpointsSetI := range endpointsSet.List() {
+	for _, endpointsSetI := range d.Get("endpoints").(*schema.Set).List() { //nolint:forcetypeassert
 		endpoints := endpointsSetI.(map[string]any) //nolint:forcetypeassert
 		for _, endpointServiceName := range endpointServiceNames {
-			config.Endpoints[endpointServiceName] = endpoints[endpointServiceName].(string) //nolint:forcetypeassert
+			overrides[endpointServiceName] = endpoints[endpointServiceName].(string) //nolint:forcetypeassert
 		}
 	}
 
+	config.Endpoints, err = resolveEndpoints(region, os.Getenv("RABATA_ENDPOINT"), overrides)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
 	client, err := config.Client()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -184,6 +366,44 @@ func providerConfigure(d *schema.ResourceData, terraformVersion string) (any, di
 	return client, nil
 }
 
+// resolveEndpoints returns the endpoint URL of each service for the region and custom domain.
+// The non-empty overrides from the endpoints block take precedence, an empty one keeps the default.
+func resolveEndpoints(region, customDomain string, overrides map[string]string) (map[string]string, error) {
+	endpoints := map[string]string{
+		"s3": "https://s3." + resolveDNSSuffix(region, customDomain),
+	}
+
+	for _, endpointServiceName := range endpointServiceNames {
+		endpoint := overrides[endpointServiceName]
+		if endpoint == "" {
+			continue
+		}
+
+		if err := validateEndpointURL(endpoint); err != nil {
+			return nil, fmt.Errorf("invalid endpoints %s: %w", endpointServiceName, err)
+		}
+
+		endpoints[endpointServiceName] = endpoint
+	}
+
+	return endpoints, nil
+}
+
+// validateEndpointURL requires an explicit scheme, without it the SDK
+// treats the host as a path and fails with unrelated connection errors.
+func validateEndpointURL(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%q must be a URL starting with https:// or http://", endpoint)
+	}
+
+	return nil
+}
+
 func endpointsSchema() *schema.Schema {
 	endpointsAttributes := make(map[string]*schema.Schema)
 
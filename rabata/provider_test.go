@@ -0,0 +1,278 @@
+package rabata
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestNormalizeRegion(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		region    string
+		expected  string
+		expectErr bool
+	}{
+		"region": {
+			region:   "eu-west-1",
+			expected: "eu-west-1",
+		},
+		"surrounding spaces": {
+			region:   " eu-west-1 ",
+			expected: "eu-west-1",
+		},
+		"upper case": {
+			region:   "EU-WEST-1",
+			expected: "eu-west-1",
+		},
+		"empty": {
+			expectErr: true,
+		},
+		"blank": {
+			region:    "  ",
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := normalizeRegion(tc.region)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveDNSSuffix(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		region       string
+		customDomain string
+		expected     string
+	}{
+		"region": {
+			region:   "us-east-1",
+			expected: "us-east-1.rabata.io",
+		},
+		"default region": {
+			expected: "eu-west-1.rabata.io",
+		},
+		"known suffix": {
+			region:   "stage",
+			expected: "stage.rabata.io",
+		},
+		"custom domain": {
+			region:       "us-east-1",
+			customDomain: "storage.example.com",
+			expected:     "storage.example.com",
+		},
+		"custom domain with trailing dot": {
+			region:       "us-east-1",
+			customDomain: " storage.example.com. ",
+			expected:     "storage.example.com",
+		},
+		"blank custom domain": {
+			region:       "us-east-1",
+			customDomain: "  ",
+			expected:     "us-east-1.rabata.io",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := resolveDNSSuffix(tc.region, tc.customDomain); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		region       string
+		customDomain string
+		overrides    map[string]string
+		expected     map[string]string
+		expectErr    bool
+	}{
+		"default": {
+			region:   "us-east-1",
+			expected: map[string]string{"s3": "https://s3.us-east-1.rabata.io"},
+		},
+		"custom domain": {
+			region:       "us-east-1",
+			customDomain: "storage.example.com",
+			expected:     map[string]string{"s3": "https://s3.storage.example.com"},
+		},
+		"override": {
+			region:    "us-east-1",
+			overrides: map[string]string{"s3": "http://localhost:9000"},
+			expected:  map[string]string{"s3": "http://localhost:9000"},
+		},
+		"empty override keeps the default": {
+			region:    "us-east-1",
+			overrides: map[string]string{"s3": ""},
+			expected:  map[string]string{"s3": "https://s3.us-east-1.rabata.io"},
+		},
+		"override without scheme": {
+			region:    "us-east-1",
+			overrides: map[string]string{"s3": "localhost:9000"},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolveEndpoints(tc.region, tc.customDomain, tc.overrides)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !maps.Equal(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestProviderPartition(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		partition   string
+		expectedARN string
+	}{
+		{name: "default", expectedARN: "arn:aws:s3:::tf-test/*"},
+		{name: "rabata", partition: "rabata", expectedARN: "arn:rabata:s3:::tf-test/*"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw := map[string]any{
+				"region":    "eu-west-1",
+				"anonymous": true,
+			}
+			if tc.partition != "" {
+				raw["partition"] = tc.partition
+			}
+
+			p := Provider()
+			if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(raw)); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			awsClient, ok := p.Meta().(*AWSClient)
+			if !ok {
+				t.Fatalf("unexpected provider configuration type: %T", p.Meta())
+			}
+
+			if got := s3BucketPublicReadStatement(awsClient.partition, "tf-test").Resources; got != tc.expectedARN {
+				t.Errorf("expected %q, got %q", tc.expectedARN, got)
+			}
+
+			if !s3PolicyResourceRegexp.MatchString(tc.expectedARN) {
+				t.Errorf("expected %q to be accepted by rabata_s3_policy_document", tc.expectedARN)
+			}
+		})
+	}
+}
+
+func TestProviderChecksumValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                     string
+		checksumValidation       string
+		expected                 string
+		expectContentMD5Disabled bool
+	}{
+		{name: "default", expected: checksumValidationWhenRequired},
+		{name: "when required", checksumValidation: checksumValidationWhenRequired, expected: checksumValidationWhenRequired},
+		{
+			name:               "when supported",
+			checksumValidation: checksumValidationWhenSupported,
+			expected:           checksumValidationWhenSupported,
+		},
+		{
+			name:                     "disabled",
+			checksumValidation:       checksumValidationDisabled,
+			expected:                 checksumValidationDisabled,
+			expectContentMD5Disabled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw := map[string]any{
+				"region":    "eu-west-1",
+				"anonymous": true,
+			}
+			if tc.checksumValidation != "" {
+				raw["checksum_validation"] = tc.checksumValidation
+			}
+
+			p := Provider()
+			if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(raw)); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			awsClient, ok := p.Meta().(*AWSClient)
+			if !ok {
+				t.Fatalf("unexpected provider configuration type: %T", p.Meta())
+			}
+
+			if awsClient.checksumValidation != tc.expected {
+				t.Errorf("expected checksum validation %q, got %q", tc.expected, awsClient.checksumValidation)
+			}
+
+			conn, ok := awsClient.s3conn.(*s3.S3)
+			if !ok {
+				t.Fatalf("unexpected S3 connection type: %T", awsClient.s3conn)
+			}
+
+			if got := aws.BoolValue(conn.Config.S3DisableContentMD5Validation); got != tc.expectContentMD5Disabled {
+				t.Errorf("expected S3DisableContentMD5Validation %t, got %t", tc.expectContentMD5Disabled, got)
+			}
+		})
+	}
+}
@@ -8,7 +8,11 @@ import (
 	"log"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -16,6 +20,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
@@ -25,7 +30,47 @@ import (
 	"github.com/rabataio/terraform-provider-rabata/rabata/internal/hashcode"
 )
 
-const s3BucketCreationTimeout = 2 * time.Minute
+// s3ErrCodeAccessControlListNotSupported is returned by ACL operations on buckets
+// with BucketOwnerEnforced object ownership.
+const s3ErrCodeAccessControlListNotSupported = "AccessControlListNotSupported"
+
+// s3ErrCodeServerSideEncryptionConfigurationNotFound is returned by GetBucketEncryption
+// for buckets without default encryption.
+const s3ErrCodeServerSideEncryptionConfigurationNotFound = "ServerSideEncryptionConfigurationNotFoundError"
+
+// s3ErrCodeNoSuchCORSConfiguration is returned by GetBucketCors for buckets without a CORS configuration.
+const s3ErrCodeNoSuchCORSConfiguration = "NoSuchCORSConfiguration"
+
+// s3ErrCodeNoSuchVersion is returned for object versions deleted since they were listed.
+const s3ErrCodeNoSuchVersion = "NoSuchVersion"
+
+// s3BucketDestroyFilterConcurrency is the number of object versions whose tags are read at a time by destroy_filter.
+const s3BucketDestroyFilterConcurrency = 8
+
+const (
+	s3BucketCreationTimeout  = 2 * time.Minute
+	s3BucketACLUpdateTimeout = 2 * time.Minute
+)
+
+const (
+	// s3BucketACLModeExclusive manages either the canned acl or the grants.
+	s3BucketACLModeExclusive = "exclusive"
+	// s3BucketACLModeCannedThenGrants puts the canned acl and then adds the grants to it.
+	s3BucketACLModeCannedThenGrants = "canned_then_grants"
+)
+
+// s3BucketGrantHeaderKeys are the attributes sent as x-amz-grant-* headers instead of a canned ACL.
+var s3BucketGrantHeaderKeys = []string{
+	"grant_full_control",
+	"grant_read",
+	"grant_read_acp",
+	"grant_write",
+	"grant_write_acp",
+}
+
+// s3BucketACLRetryCodes are retried while updating the bucket ACL: the bucket may not
+// be visible yet right after its creation, and ACL puts are throttled under load.
+var s3BucketACLRetryCodes = append([]string{s3.ErrCodeNoSuchBucket}, s3ThrottlingErrorCodes...)
 
 func resourceRabataS3Bucket() *schema.Resource {
 	return &schema.Resource{
@@ -37,6 +82,12 @@ func resourceRabataS3Bucket() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		CustomizeDiff: resourceRabataS3BucketCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Update: schema.DefaultTimeout(s3BucketACLUpdateTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"bucket": {
 				Type:          schema.TypeString,
@@ -45,6 +96,15 @@ func resourceRabataS3Bucket() *schema.Resource {
 				ForceNew:      true,
 				ConflictsWith: []string{"bucket_prefix"},
 				ValidateFunc:  validation.StringLenBetween(0, 63), //nolint:mnd
+				Description: "The name of the bucket. Names containing dots are accepted, but with virtual " +
+					"hosted-style addressing the name becomes part of the endpoint hostname, which the " +
+					"wildcard TLS certificate of the endpoint does not match. Set `s3_force_path_style = true` " +
+					"in the provider configuration to use such buckets over TLS. The plan only logs a " +
+					"`[WARN]` message about it, visible with `TF_LOG=WARN`. The name is validated at plan time " +
+					"against the Rabata bucket naming rules, which are the same in every region: 3 to 63 lowercase " +
+					"letters, numbers, periods and hyphens, starting and ending with a letter or number. The check " +
+					"runs when the plan is computed rather than as attribute validation, which does not know the " +
+					"provider region.",
 			},
 
 			"bucket_prefix": {
@@ -72,17 +132,65 @@ func resourceRabataS3Bucket() *schema.Resource {
 			},
 
 			"acl": {
+				Type:     schema.TypeString,
+				Default:  "private",
+				Optional: true,
+			},
+
+			"acl_mode": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  s3BucketACLModeExclusive,
+				ValidateFunc: validation.StringInSlice([]string{
+					s3BucketACLModeExclusive,
+					s3BucketACLModeCannedThenGrants,
+				}, false),
+				Description: "How `acl` and `grant` are combined. With `exclusive`, only one of them can be set. " +
+					"With `canned_then_grants`, the canned `acl` is put first and the `grant` permissions are " +
+					"then added to the grants of the canned ACL. Only the permissions of the configured grantees " +
+					"are read back, so the grants added by the canned ACL and grants of other grantees do not " +
+					"show up as a diff.",
+			},
+
+			"grant_full_control": {
 				Type:          schema.TypeString,
-				Default:       "private",
 				Optional:      true,
-				ConflictsWith: []string{"grant"},
+				ConflictsWith: []string{"acl", "grant"},
+				ValidateFunc:  validateS3GrantHeader,
 			},
 
-			"grant": {
-				Type:          schema.TypeSet,
+			"grant_read": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"acl", "grant"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
+			"grant_read_acp": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"acl", "grant"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
+			"grant_write": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"acl", "grant"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
+			"grant_write_acp": {
+				Type:          schema.TypeString,
 				Optional:      true,
-				Set:           grantHash,
-				ConflictsWith: []string{"acl"},
+				ConflictsWith: []string{"acl", "grant"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
+			"grant": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Set:      grantHash,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"id": {
@@ -126,11 +234,125 @@ func resourceRabataS3Bucket() *schema.Resource {
 				Computed: true,
 			},
 
+			"creation_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"server_side_encryption_configuration": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"sse_algorithm": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"kms_master_key_id": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"bucket_key_enabled": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+
+			"cors_rule": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Description: "The CORS configuration of the bucket, replaced as a whole by PutBucketCors. " +
+					"Removing every block leaves the configuration as it is, so that CORS may also be " +
+					"managed outside of this resource, but then cors_rule must not be set here as well.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"allowed_headers": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"allowed_methods": {
+							Type:     schema.TypeList,
+							Required: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice([]string{"GET", "PUT", "HEAD", "POST", "DELETE"}, false),
+							},
+						},
+						"allowed_origins": {
+							Type:     schema.TypeList,
+							Required: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"expose_headers": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"max_age_seconds": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
+
 			"force_destroy": {
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
 			},
+
+			"destroy_filter": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Description: "Restricts force_destroy to the object versions carrying all of the given tags. " +
+					"The tags of every object version are read with one GetObjectTagging request per version. " +
+					"The bucket and its delete markers are only deleted if no object version is left, otherwise " +
+					"the destroy fails after deleting the matching object versions.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tags": {
+							Type:     schema.TypeMap,
+							Required: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+
+			"fail_if_not_empty": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"check_global_uniqueness": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"relaxed_bucket_name_validation": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Only reject characters that S3 never accepts in bucket names, allowing uppercase " +
+					"letters and underscores in every region, for existing buckets with legacy names.",
+			},
 		},
 	}
 }
@@ -139,6 +361,10 @@ func resourceRabataS3BucketCreate(ctx context.Context, d *schema.ResourceData, m
 	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
 	s3conn := awsClient.s3conn
 
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
 	// Get the bucket and acl
 	var bucket string
 	if v, ok := d.GetOk("bucket"); ok {
@@ -157,7 +383,16 @@ func resourceRabataS3BucketCreate(ctx context.Context, d *schema.ResourceData, m
 		Bucket: aws.String(bucket),
 	}
 
-	if acl, ok := d.GetOk("acl"); ok {
+	// Grant headers and a canned ACL are mutually exclusive in a single request,
+	// the grants are applied with the bucket creation and no PutBucketAcl is needed.
+	if hasS3BucketGrantHeaders(d) {
+		req.GrantFullControl = s3ObjectGrantHeader(d, "grant_full_control")
+		req.GrantRead = s3ObjectGrantHeader(d, "grant_read")
+		req.GrantReadACP = s3ObjectGrantHeader(d, "grant_read_acp")
+		req.GrantWrite = s3ObjectGrantHeader(d, "grant_write")
+		req.GrantWriteACP = s3ObjectGrantHeader(d, "grant_write_acp")
+		log.Printf("[DEBUG] S3 bucket %s has grant headers", bucket)
+	} else if acl, ok := d.GetOk("acl"); ok {
 		acl := acl.(string) //nolint:forcetypeassert
 		req.ACL = aws.String(acl)
 		log.Printf("[DEBUG] S3 bucket %s has canned ACL %s", bucket, acl)
@@ -174,38 +409,22 @@ func resourceRabataS3BucketCreate(ctx context.Context, d *schema.ResourceData, m
 		}
 	}
 
-	if err := validateS3BucketName(bucket); err != nil {
-		return diag.Errorf("error validating S3 bucket name: %s", err)
+	validateName := validateS3BucketName
+	if d.Get("relaxed_bucket_name_validation").(bool) { //nolint:forcetypeassert
+		validateName = validateS3BucketNameRelaxed
 	}
 
-	err := retry.RetryContext(ctx, 5*time.Minute, func() *retry.RetryError { //nolint:mnd
-		log.Printf("[DEBUG] Trying to create new S3 bucket: %q", bucket)
-
-		_, err := s3conn.CreateBucketWithContext(ctx, req)
-
-		var awsErr awserr.Error
-
-		if errors.As(err, &awsErr) {
-			if awsErr.Code() == "OperationAborted" {
-				log.Printf("[WARN] Got an error while trying to create S3 bucket %s: %s", bucket, err)
-
-				return retry.RetryableError(
-					fmt.Errorf("error creating S3 bucket %s, retrying: %w", bucket, err))
-			}
-		}
+	if err := validateName(bucket, awsRegion); err != nil {
+		return diag.Errorf("error validating S3 bucket name: %s", err)
+	}
 
-		if err != nil {
-			return retry.NonRetryableError(err)
+	if d.Get("check_global_uniqueness").(bool) { //nolint:forcetypeassert
+		if err := checkS3BucketNameAvailable(ctx, s3conn, bucket); err != nil {
+			return diag.FromErr(err)
 		}
-
-		return nil
-	})
-
-	if isResourceTimeoutError(err) {
-		_, err = s3conn.CreateBucketWithContext(ctx, req)
 	}
 
-	if err != nil {
+	if err := createS3Bucket(ctx, s3conn, req, 5*time.Minute); err != nil { //nolint:mnd
 		return diag.Errorf("error creating S3 bucket: %s", err)
 	}
 
@@ -216,16 +435,47 @@ func resourceRabataS3BucketCreate(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceRabataS3BucketUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
+	s3conn := awsClient.s3conn
 
-	if d.HasChange("acl") && !d.IsNewResource() {
-		if err := resourceRabataS3BucketACLUpdate(ctx, s3conn, d); err != nil {
-			return diag.FromErr(err)
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Get("acl_mode").(string) == s3BucketACLModeCannedThenGrants { //nolint:forcetypeassert
+		if d.HasChanges("acl", "acl_mode", "grant") {
+			if err := resourceRabataS3BucketCannedThenGrantsUpdate(ctx, s3conn, d); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	} else {
+		if d.HasChanges(s3BucketGrantHeaderKeys...) && !d.IsNewResource() {
+			if err := resourceRabataS3BucketGrantHeadersUpdate(ctx, s3conn, d); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+
+		if d.HasChanges("acl", "acl_mode") && !d.IsNewResource() && !hasS3BucketGrantHeaders(d) {
+			if err := resourceRabataS3BucketACLUpdate(ctx, s3conn, d); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+
+		// Switching back from canned_then_grants puts the canned ACL, which replaces the grants.
+		if shouldUpdateS3BucketGrants(
+			d.HasChange("grant"),
+			d.HasChange("acl_mode") && !d.IsNewResource(),
+			d.Get("grant").(*schema.Set).Len() > 0, //nolint:forcetypeassert
+			hasS3BucketGrantHeaders(d),
+		) {
+			if err := resourceRabataS3BucketGrantsUpdate(ctx, s3conn, d); err != nil {
+				return diag.FromErr(err)
+			}
 		}
 	}
 
-	if d.HasChange("grant") {
-		if err := resourceRabataS3BucketGrantsUpdate(ctx, s3conn, d); err != nil {
+	if d.HasChange("cors_rule") {
+		if err := resourceRabataS3BucketCorsUpdate(ctx, s3conn, d); err != nil {
 			return diag.FromErr(err)
 		}
 	}
@@ -283,8 +533,12 @@ func resourceRabataS3BucketRead(ctx context.Context, d *schema.ResourceData, met
 
 	d.Set("bucket_domain_name", bucketDomainName) //nolint:errcheck
 
-	// Read the Grant ACL. Reset if `acl` (canned ACL) is set.
-	if acl, ok := d.GetOk("acl"); ok && acl.(string) != "private" { //nolint:forcetypeassert
+	cannedThenGrants := d.Get("acl_mode").(string) == s3BucketACLModeCannedThenGrants //nolint:forcetypeassert
+
+	// Read the Grant ACL. Reset if `acl` (canned ACL) or grant headers are set,
+	// the grants they create are not part of the configuration.
+	if acl, ok := d.GetOk("acl"); (ok && acl.(string) != "private" && !cannedThenGrants) || //nolint:forcetypeassert
+		hasS3BucketGrantHeaders(d) {
 		if err := d.Set("grant", nil); err != nil {
 			return diag.Errorf("error resetting grant %s", err)
 		}
@@ -294,15 +548,28 @@ func resourceRabataS3BucketRead(ctx context.Context, d *schema.ResourceData, met
 				Bucket: aws.String(d.Id()),
 			})
 		})
-		if err != nil {
+
+		switch {
+		case isAWSErr(err, s3ErrCodeAccessControlListNotSupported, ""):
+			// ACLs are disabled on buckets with BucketOwnerEnforced object ownership.
+			log.Printf("[DEBUG] S3 bucket: %s, ACLs are not supported, skipping grants", d.Id())
+
+			if err := d.Set("grant", nil); err != nil {
+				return diag.Errorf("error resetting grant %s", err)
+			}
+		case err != nil:
 			return diag.Errorf("error getting S3 Bucket (%s) ACL: %s", d.Id(), err)
-		}
+		default:
+			log.Printf("[DEBUG] S3 bucket: %s, read ACL grants policy: %+v", d.Id(), apResponse)
 
-		log.Printf("[DEBUG] S3 bucket: %s, read ACL grants policy: %+v", d.Id(), apResponse)
+			grants := flattenGrants(apResponse.(*s3.GetBucketAclOutput)) //nolint:forcetypeassert
+			if cannedThenGrants {
+				grants = filterConfiguredGrants(grants, d.Get("grant").(*schema.Set).List()) //nolint:forcetypeassert
+			}
 
-		grants := flattenGrants(apResponse.(*s3.GetBucketAclOutput)) //nolint:forcetypeassert
-		if err := d.Set("grant", schema.NewSet(grantHash, grants)); err != nil {
-			return diag.Errorf("error setting grant %s", err)
+			if err := d.Set("grant", schema.NewSet(grantHash, grants)); err != nil {
+				return diag.Errorf("error setting grant %s", err)
+			}
 		}
 	}
 
@@ -313,7 +580,7 @@ func resourceRabataS3BucketRead(ctx context.Context, d *schema.ResourceData, met
 			// is not compatible with many non-AWS implementations. Instead, pass
 			// the provider s3_force_path_style configuration, which defaults to
 			// false, but allows override.
-			r.Config.S3ForcePathStyle = s3conn.Config.S3ForcePathStyle
+			r.Config.S3ForcePathStyle = aws.Bool(awsClient.s3ForcePathStyle)
 		})
 	})
 	if err != nil {
@@ -328,19 +595,182 @@ func resourceRabataS3BucketRead(ctx context.Context, d *schema.ResourceData, met
 	d.Set("bucket_regional_domain_name", bucketDomainName) //nolint:errcheck
 
 	a := arn.ARN{
-		Partition: "aws",
+		Partition: awsClient.partition,
 		Service:   "s3",
 		Resource:  d.Id(),
 	}.String()
 	d.Set("arn", a) //nolint:errcheck
 
+	// The creation date never changes, and ListBuckets lists every bucket of the account,
+	// so it is only read when missing from the state, e.g. after a create or an import.
+	if d.Get("creation_date").(string) == "" { //nolint:forcetypeassert
+		d.Set("creation_date", findS3BucketCreationDate(ctx, s3conn, d.Id())) //nolint:errcheck
+	}
+
+	cors, err := s3conn.GetBucketCorsWithContext(ctx, &s3.GetBucketCorsInput{
+		Bucket: aws.String(d.Id()),
+	})
+
+	switch {
+	case isAWSErr(err, s3ErrCodeNoSuchCORSConfiguration, "") || isS3NotImplementedErr(err):
+		log.Printf("[DEBUG] S3 Bucket (%s) has no CORS configuration: %s", d.Id(), err)
+		d.Set("cors_rule", nil) //nolint:errcheck
+	case isAWSErrRequestFailureStatusCode(err, http.StatusForbidden):
+		// cors_rule is computed, keeping the state avoids a diff for CORS managed elsewhere.
+		log.Printf("[WARN] S3 Bucket (%s) CORS configuration not readable, access denied: %s", d.Id(), err)
+	case err != nil:
+		return diag.Errorf("error getting S3 Bucket (%s) CORS configuration: %s", d.Id(), err)
+	default:
+		if err := d.Set("cors_rule", flattenS3CORSRules(cors.CORSRules)); err != nil {
+			return diag.Errorf("error setting cors_rule: %s", err)
+		}
+	}
+
+	encryption, err := s3conn.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: aws.String(d.Id()),
+	})
+
+	switch {
+	case isAWSErr(err, s3ErrCodeServerSideEncryptionConfigurationNotFound, "") || isS3NotImplementedErr(err):
+		log.Printf("[DEBUG] S3 Bucket (%s) has no default encryption: %s", d.Id(), err)
+		d.Set("server_side_encryption_configuration", nil) //nolint:errcheck
+	case isAWSErrRequestFailureStatusCode(err, http.StatusForbidden):
+		// Credentials without s3:GetEncryptionConfiguration can still manage the bucket.
+		log.Printf("[WARN] S3 Bucket (%s) encryption not readable, access denied: %s", d.Id(), err)
+		d.Set("server_side_encryption_configuration", nil) //nolint:errcheck
+	case err != nil:
+		return diag.Errorf("error getting S3 Bucket (%s) encryption: %s", d.Id(), err)
+	default:
+		if err := d.Set("server_side_encryption_configuration",
+			flattenS3ServerSideEncryptionConfiguration(encryption.ServerSideEncryptionConfiguration)); err != nil {
+			return diag.Errorf("error setting server_side_encryption_configuration: %s", err)
+		}
+	}
+
 	return nil
 }
 
+// resourceRabataS3BucketCorsUpdate puts the configured CORS rules, or deletes the CORS configuration
+// if no rule is left.
+func resourceRabataS3BucketCorsUpdate(ctx context.Context, s3conn s3iface.S3API, d *schema.ResourceData) error {
+	rules := expandS3CORSRules(d.Get("cors_rule").([]any)) //nolint:forcetypeassert
+
+	if len(rules) == 0 {
+		log.Printf("[DEBUG] Deleting S3 Bucket (%s) CORS configuration", d.Id())
+
+		_, err := s3conn.DeleteBucketCorsWithContext(ctx, &s3.DeleteBucketCorsInput{
+			Bucket: aws.String(d.Id()),
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting S3 Bucket (%s) CORS configuration: %w", d.Id(), err)
+		}
+
+		return nil
+	}
+
+	log.Printf("[DEBUG] Putting S3 Bucket (%s) CORS configuration: %d rules", d.Id(), len(rules))
+
+	_, err := s3conn.PutBucketCorsWithContext(ctx, &s3.PutBucketCorsInput{
+		Bucket: aws.String(d.Id()),
+		CORSConfiguration: &s3.CORSConfiguration{
+			CORSRules: rules,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error putting S3 Bucket (%s) CORS configuration: %w", d.Id(), err)
+	}
+
+	return nil
+}
+
+func expandS3CORSRules(l []any) []*s3.CORSRule {
+	rules := make([]*s3.CORSRule, 0, len(l))
+
+	for _, v := range l {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		rule := &s3.CORSRule{
+			AllowedHeaders: aws.StringSlice(expandStringList(m["allowed_headers"].([]any))), //nolint:forcetypeassert
+			AllowedMethods: aws.StringSlice(expandStringList(m["allowed_methods"].([]any))), //nolint:forcetypeassert
+			AllowedOrigins: aws.StringSlice(expandStringList(m["allowed_origins"].([]any))), //nolint:forcetypeassert
+			ExposeHeaders:  aws.StringSlice(expandStringList(m["expose_headers"].([]any))),  //nolint:forcetypeassert
+		}
+
+		if v, ok := m["max_age_seconds"].(int); ok && v > 0 {
+			rule.MaxAgeSeconds = aws.Int64(int64(v))
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+func flattenS3ServerSideEncryptionConfiguration(c *s3.ServerSideEncryptionConfiguration) []any {
+	if c == nil {
+		return nil
+	}
+
+	rules := make([]any, 0, len(c.Rules))
+
+	for _, rule := range c.Rules {
+		m := map[string]any{
+			"bucket_key_enabled": aws.BoolValue(rule.BucketKeyEnabled),
+		}
+
+		if v := rule.ApplyServerSideEncryptionByDefault; v != nil {
+			m["sse_algorithm"] = aws.StringValue(v.SSEAlgorithm)
+			m["kms_master_key_id"] = aws.StringValue(v.KMSMasterKeyID)
+		}
+
+		rules = append(rules, m)
+	}
+
+	return []any{map[string]any{"rule": rules}}
+}
+
+// findS3BucketCreationDate returns the creation date of the bucket from the bucket list,
+// or an empty string if the endpoint doesn't list it.
+func findS3BucketCreationDate(ctx context.Context, conn s3iface.S3API, bucket string) string {
+	out, err := conn.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		log.Printf("[WARN] Error listing S3 Buckets to get creation date of %s: %s", bucket, err)
+
+		return ""
+	}
+
+	for _, b := range out.Buckets {
+		if aws.StringValue(b.Name) == bucket && b.CreationDate != nil {
+			return b.CreationDate.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return ""
+}
+
 func resourceRabataS3BucketDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
 	s3conn := awsClient.s3conn
 
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	forceDestroy, _ := d.Get("force_destroy").(bool)
+
+	if failIfNotEmpty, _ := d.Get("fail_if_not_empty").(bool); failIfNotEmpty && !forceDestroy {
+		if err := checkS3BucketEmpty(ctx, s3conn, d.Id()); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	log.Printf("[DEBUG] S3 Delete Bucket: %s", d.Id())
 	_, err := s3conn.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{
 		Bucket: aws.String(d.Id()),
@@ -351,7 +781,7 @@ func resourceRabataS3BucketDelete(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	if isAWSErr(err, "BucketNotEmpty", "") {
-		if d.Get("force_destroy").(bool) { //nolint:forcetypeassert
+		if forceDestroy {
 			// Use a S3 service client that can handle multiple slashes in URIs.
 			// While rabata_s3_bucket_object resources cannot create these object
 			// keys, other AWS services and applications using the S3 Bucket can.
@@ -360,9 +790,26 @@ func resourceRabataS3BucketDelete(ctx context.Context, d *schema.ResourceData, m
 			// bucket may have things delete them
 			log.Printf("[DEBUG] S3 Bucket attempting to forceDestroy %+v", err)
 
+			if v, ok := d.GetOk("destroy_filter"); ok && v.([]any)[0] != nil { //nolint:forcetypeassert
+				tfMap := v.([]any)[0].(map[string]any)                  //nolint:forcetypeassert
+				tags := expandStringMap(tfMap["tags"].(map[string]any)) //nolint:forcetypeassert
+
+				kept, err := deleteS3ObjectsByTags(ctx, s3conn, d.Id(), tags, s3BucketDestroyFilterConcurrency)
+				if err != nil {
+					return diag.Errorf("error S3 Bucket force_destroy: %s", err)
+				}
+
+				if kept > 0 {
+					return diag.Errorf("error S3 Bucket force_destroy: S3 Bucket (%s) still contains %d object "+
+						"versions not matching destroy_filter, the bucket is not deleted", d.Id(), kept)
+				}
+
+				return resourceRabataS3BucketDelete(ctx, d, meta)
+			}
+
 			// Delete everything including locked objects.
 			// Don't ignore any object errors or we could recurse infinitely.
-			err = deleteAllS3Objects(ctx, s3conn, d.Id(), "", false, false)
+			err = deleteAllS3Objects(ctx, s3conn, d.Id(), "", "", "", false, false)
 			if err != nil {
 				return diag.Errorf("error S3 Bucket force_destroy: %s", err)
 			}
@@ -379,7 +826,178 @@ func resourceRabataS3BucketDelete(ctx context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func resourceRabataS3BucketGrantsUpdate(ctx context.Context, s3conn *s3.S3, d *schema.ResourceData) error {
+// deleteS3ObjectsByTags deletes the object versions of bucket carrying all of tags
+// and returns the number of object versions kept.
+// The tags of at most maxConcurrency object versions are read at a time. Once no version is kept,
+// the delete markers are deleted as well, as they would keep the bucket from being deleted.
+func deleteS3ObjectsByTags(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket string,
+	tags map[string]string,
+	maxConcurrency int,
+) (int, error) {
+	var kept atomic.Int64
+
+	err := forEachS3ObjectVersion(ctx, conn, bucket, false, maxConcurrency, func(key, versionID string) error {
+		out, err := conn.GetObjectTaggingWithContext(ctx, &s3.GetObjectTaggingInput{
+			Bucket:    aws.String(bucket),
+			Key:       aws.String(key),
+			VersionId: aws.String(versionID),
+		})
+		if isAWSErr(err, s3.ErrCodeNoSuchKey, "") || isAWSErr(err, s3ErrCodeNoSuchVersion, "") {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("error reading S3 Bucket (%s) Object (%s) version (%s) tags: %w", bucket, key, versionID, err)
+		}
+
+		objectTags := make(map[string]string, len(out.TagSet))
+		for _, tag := range out.TagSet {
+			objectTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+
+		for k, v := range tags {
+			if value, ok := objectTags[k]; !ok || value != v {
+				log.Printf("[DEBUG] Keeping S3 Bucket (%s) Object (%s) version (%s) not matching destroy_filter",
+					bucket, key, versionID)
+
+				kept.Add(1)
+
+				return nil
+			}
+		}
+
+		return deleteS3ObjectVersion(ctx, conn, bucket, key, versionID, "", "", false)
+	})
+	if err != nil || kept.Load() > 0 {
+		return int(kept.Load()), err
+	}
+
+	err = forEachS3ObjectVersion(ctx, conn, bucket, true, maxConcurrency, func(key, versionID string) error {
+		return deleteS3ObjectVersion(ctx, conn, bucket, key, versionID, "", "", false)
+	})
+
+	return 0, err
+}
+
+// forEachS3ObjectVersion calls f with the object versions of bucket, or with its delete markers
+// if deleteMarkers is true. The listing is fed page by page to maxConcurrency workers,
+// and stops once ctx is done.
+func forEachS3ObjectVersion(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket string,
+	deleteMarkers bool,
+	maxConcurrency int,
+	f func(key, versionID string) error,
+) error {
+	type objectVersion struct {
+		key, versionID string
+	}
+
+	versions := make(chan objectVersion)
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+
+	for range maxConcurrency {
+		wg.Go(func() {
+			for version := range versions {
+				if err := f(version.key, version.versionID); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		})
+	}
+
+	send := func(key, versionID *string) bool {
+		select {
+		case versions <- objectVersion{key: aws.StringValue(key), versionID: aws.StringValue(versionID)}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	err := conn.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	}, func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+		if deleteMarkers {
+			for _, marker := range page.DeleteMarkers {
+				if !send(marker.Key, marker.VersionId) {
+					return false
+				}
+			}
+
+			return true
+		}
+
+		for _, version := range page.Versions {
+			if !send(version.Key, version.VersionId) {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	close(versions)
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		errs = append(errs, fmt.Errorf("error listing S3 Bucket (%s) object versions: %w", bucket, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkS3BucketEmpty returns an error listing the first few keys of the bucket if it is not empty.
+func checkS3BucketEmpty(ctx context.Context, conn s3iface.S3API, bucket string) error {
+	const maxListedKeys = 5
+
+	out, err := conn.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		MaxKeys: aws.Int64(maxListedKeys),
+	})
+	if isAWSErr(err, s3.ErrCodeNoSuchBucket, "") {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error listing S3 Bucket (%s) objects: %w", bucket, err)
+	}
+
+	if len(out.Contents) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(out.Contents))
+	for _, object := range out.Contents {
+		keys = append(keys, strconv.Quote(aws.StringValue(object.Key)))
+	}
+
+	more := ""
+	if aws.BoolValue(out.IsTruncated) {
+		more = ", ..."
+	}
+
+	return fmt.Errorf("S3 Bucket (%s) is not empty and force_destroy is false, it contains objects: %s%s. "+
+		"Delete the objects or set force_destroy = true to delete them with the bucket",
+		bucket, strings.Join(keys, ", "), more)
+}
+
+func resourceRabataS3BucketGrantsUpdate(ctx context.Context, s3conn s3iface.S3API, d *schema.ResourceData) error {
 	bucket := d.Get("bucket").(string)               //nolint:forcetypeassert
 	rawGrants := d.Get("grant").(*schema.Set).List() //nolint:forcetypeassert
 
@@ -390,7 +1008,7 @@ func resourceRabataS3BucketGrantsUpdate(ctx context.Context, s3conn *s3.S3, d *s
 			return fmt.Errorf("error fallback to canned ACL, %w", err)
 		}
 	} else {
-		apResponse, err := retryOnAWSCode(ctx, "NoSuchBucket", func() (any, error) {
+		apResponse, err := retryOnAWSCodes(ctx, d.Timeout(schema.TimeoutUpdate), s3BucketACLRetryCodes, func() (any, error) {
 			return s3conn.GetBucketAclWithContext(ctx, &s3.GetBucketAclInput{
 				Bucket: aws.String(d.Id()),
 			})
@@ -402,34 +1020,7 @@ func resourceRabataS3BucketGrantsUpdate(ctx context.Context, s3conn *s3.S3, d *s
 		ap := apResponse.(*s3.GetBucketAclOutput) //nolint:forcetypeassert
 		log.Printf("[DEBUG] S3 bucket: %s, read ACL grants policy: %+v", d.Id(), ap)
 
-		grants := make([]*s3.Grant, 0, len(rawGrants))
-
-		for _, rawGrant := range rawGrants {
-			log.Printf("[DEBUG] S3 bucket: %s, put grant: %#v", bucket, rawGrant)
-			grantMap := rawGrant.(map[string]any) //nolint:forcetypeassert
-
-			for _, rawPermission := range grantMap["permissions"].(*schema.Set).List() { //nolint:forcetypeassert
-				ge := &s3.Grantee{}
-				if i, ok := grantMap["id"].(string); ok && i != "" {
-					ge.SetID(i)
-				}
-
-				if t, ok := grantMap["type"].(string); ok && t != "" {
-					ge.SetType(t)
-				}
-
-				if u, ok := grantMap["uri"].(string); ok && u != "" {
-					ge.SetURI(u)
-				}
-
-				//nolint:forcetypeassert
-				g := &s3.Grant{
-					Grantee:    ge,
-					Permission: aws.String(rawPermission.(string)),
-				}
-				grants = append(grants, g)
-			}
-		}
+		grants := expandGrants(bucket, rawGrants)
 
 		grantsInput := &s3.PutBucketAclInput{
 			Bucket: aws.String(bucket),
@@ -441,7 +1032,7 @@ func resourceRabataS3BucketGrantsUpdate(ctx context.Context, s3conn *s3.S3, d *s
 
 		log.Printf("[DEBUG] S3 bucket: %s, put Grants: %#v", bucket, grantsInput)
 
-		_, err = retryOnAWSCode(ctx, "NoSuchBucket", func() (any, error) {
+		_, err = retryOnAWSCodes(ctx, d.Timeout(schema.TimeoutUpdate), s3BucketACLRetryCodes, func() (any, error) {
 			return s3conn.PutBucketAclWithContext(ctx, grantsInput)
 		})
 		if err != nil {
@@ -452,7 +1043,111 @@ func resourceRabataS3BucketGrantsUpdate(ctx context.Context, s3conn *s3.S3, d *s
 	return nil
 }
 
-func resourceRabataS3BucketACLUpdate(ctx context.Context, s3conn *s3.S3, d *schema.ResourceData) error {
+// resourceRabataS3BucketCannedThenGrantsUpdate puts the canned ACL, then puts its grants
+// together with the configured grants.
+func resourceRabataS3BucketCannedThenGrantsUpdate(
+	ctx context.Context,
+	s3conn s3iface.S3API,
+	d *schema.ResourceData,
+) error {
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+
+	if err := resourceRabataS3BucketACLUpdate(ctx, s3conn, d); err != nil {
+		return err
+	}
+
+	rawGrants := d.Get("grant").(*schema.Set).List() //nolint:forcetypeassert
+	if len(rawGrants) == 0 {
+		return nil
+	}
+
+	apResponse, err := retryOnAWSCodes(ctx, d.Timeout(schema.TimeoutUpdate), s3BucketACLRetryCodes, func() (any, error) {
+		return s3conn.GetBucketAclWithContext(ctx, &s3.GetBucketAclInput{
+			Bucket: aws.String(bucket),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error getting S3 Bucket (%s) ACL: %w", bucket, err)
+	}
+
+	ap := apResponse.(*s3.GetBucketAclOutput) //nolint:forcetypeassert
+	grants := ap.Grants
+
+	for _, grant := range expandGrants(bucket, rawGrants) {
+		if !slices.ContainsFunc(grants, func(g *s3.Grant) bool { return isSameGrant(g, grant) }) {
+			grants = append(grants, grant)
+		}
+	}
+
+	grantsInput := &s3.PutBucketAclInput{
+		Bucket: aws.String(bucket),
+		AccessControlPolicy: &s3.AccessControlPolicy{
+			Grants: grants,
+			Owner:  ap.Owner,
+		},
+	}
+
+	log.Printf("[DEBUG] S3 bucket: %s, put canned ACL grants and Grants: %#v", bucket, grantsInput)
+
+	_, err = retryOnAWSCodes(ctx, d.Timeout(schema.TimeoutUpdate), s3BucketACLRetryCodes, func() (any, error) {
+		return s3conn.PutBucketAclWithContext(ctx, grantsInput)
+	})
+	if err != nil {
+		return fmt.Errorf("error putting S3 Grants: %w", err)
+	}
+
+	return nil
+}
+
+// resourceRabataS3BucketGrantHeadersUpdate replaces the bucket ACL with the grant headers,
+// or with the canned ACL once all of them are removed.
+func resourceRabataS3BucketGrantHeadersUpdate(ctx context.Context, s3conn s3iface.S3API, d *schema.ResourceData) error {
+	if !hasS3BucketGrantHeaders(d) {
+		return resourceRabataS3BucketACLUpdate(ctx, s3conn, d)
+	}
+
+	i := &s3.PutBucketAclInput{
+		Bucket:           aws.String(d.Get("bucket").(string)), //nolint:forcetypeassert
+		GrantFullControl: s3ObjectGrantHeader(d, "grant_full_control"),
+		GrantRead:        s3ObjectGrantHeader(d, "grant_read"),
+		GrantReadACP:     s3ObjectGrantHeader(d, "grant_read_acp"),
+		GrantWrite:       s3ObjectGrantHeader(d, "grant_write"),
+		GrantWriteACP:    s3ObjectGrantHeader(d, "grant_write_acp"),
+	}
+	log.Printf("[DEBUG] S3 put bucket grant headers: %#v", i)
+
+	_, err := retryOnAWSCodes(ctx, d.Timeout(schema.TimeoutUpdate), s3BucketACLRetryCodes, func() (any, error) {
+		return s3conn.PutBucketAclWithContext(ctx, i)
+	})
+	if err != nil {
+		return fmt.Errorf("error putting S3 grant headers: %w", err)
+	}
+
+	return nil
+}
+
+// shouldUpdateS3BucketGrants returns true if the grant set has to be put after a change of grant or acl_mode.
+// Without grants, resourceRabataS3BucketGrantsUpdate falls back to the canned ACL,
+// which would replace the ACL just put from the grant headers.
+func shouldUpdateS3BucketGrants(grantChanged, aclModeChanged, hasGrants, hasGrantHeaders bool) bool {
+	if !grantChanged && !aclModeChanged {
+		return false
+	}
+
+	return hasGrants || !hasGrantHeaders
+}
+
+func hasS3BucketGrantHeaders(d *schema.ResourceData) bool {
+	for _, k := range s3BucketGrantHeaderKeys {
+		if _, ok := d.GetOk(k); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func resourceRabataS3BucketACLUpdate(ctx context.Context, s3conn s3iface.S3API, d *schema.ResourceData) error {
 	acl := d.Get("acl").(string)       //nolint:forcetypeassert
 	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
 
@@ -462,7 +1157,7 @@ func resourceRabataS3BucketACLUpdate(ctx context.Context, s3conn *s3.S3, d *sche
 	}
 	log.Printf("[DEBUG] S3 put bucket ACL: %#v", i)
 
-	_, err := retryOnAWSCode(ctx, s3.ErrCodeNoSuchBucket, func() (any, error) {
+	_, err := retryOnAWSCodes(ctx, d.Timeout(schema.TimeoutUpdate), s3BucketACLRetryCodes, func() (any, error) {
 		return s3conn.PutBucketAclWithContext(ctx, i)
 	})
 	if err != nil {
@@ -472,30 +1167,215 @@ func resourceRabataS3BucketACLUpdate(ctx context.Context, s3conn *s3.S3, d *sche
 	return nil
 }
 
-// validateS3BucketName validates any S3 bucket name.
-func validateS3BucketName(value string) error {
+func resourceRabataS3BucketCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	// acl has a default, so only a configured acl conflicts with grant.
+	if d.Get("acl_mode").(string) == s3BucketACLModeExclusive && //nolint:forcetypeassert
+		!d.GetRawConfig().GetAttr("acl").IsNull() && d.Get("grant").(*schema.Set).Len() > 0 { //nolint:forcetypeassert
+		return errors.New(`"acl" and "grant" cannot both be set unless acl_mode is "canned_then_grants"`)
+	}
+
+	if d.Get("acl_mode").(string) == s3BucketACLModeCannedThenGrants { //nolint:forcetypeassert
+		for _, k := range s3BucketGrantHeaderKeys {
+			if _, ok := d.GetOk(k); ok {
+				return fmt.Errorf("%q cannot be set when acl_mode is %q", k, s3BucketACLModeCannedThenGrants)
+			}
+		}
+	}
+
+	awsClient, ok := meta.(*AWSClient)
+	if !ok || awsClient.s3conn == nil {
+		return nil
+	}
+
+	if err := checkS3BucketACLOwnership(ctx, awsClient.s3conn, d); err != nil {
+		return err
+	}
+
+	// Bucket names generated from bucket_prefix are unknown at plan time,
+	// but a prefix with dots produces a dotted name as well.
+	validateName := validateS3BucketName
+	if d.Get("relaxed_bucket_name_validation").(bool) { //nolint:forcetypeassert
+		validateName = validateS3BucketNameRelaxed
+	}
+
+	name := d.Get("bucket").(string) //nolint:forcetypeassert
+	if name == "" {
+		name = d.Get("bucket_prefix").(string) //nolint:forcetypeassert
+	} else if err := validateName(name, awsClient.region); err != nil {
+		return fmt.Errorf("error validating S3 bucket name: %w", err)
+	}
+
+	if !strings.Contains(name, ".") || awsClient.insecure || awsClient.s3ForcePathStyle {
+		return nil
+	}
+
+	// Virtual hosted addressing puts the bucket name into the hostname,
+	// and the wildcard TLS certificate of the endpoint only matches a single label.
+	// The certificate is not verified when the provider is insecure. CustomizeDiff cannot
+	// return warnings, and endpoints with a matching certificate work, so the plan is not blocked.
+	log.Printf("[WARN] S3 bucket name %q contains dots, which may not be compatible with virtual hosted-style "+
+		"addressing over TLS: set s3_force_path_style = true in the provider configuration if requests fail", name)
+
+	return nil
+}
+
+// checkS3BucketACLOwnership returns an error if the plan changes the ACL of an existing bucket
+// whose object ownership is BucketOwnerEnforced, which rejects every ACL but the private one.
+// The ownership controls are only read when the ACL changes, and endpoints not implementing them are ignored.
+func checkS3BucketACLOwnership(ctx context.Context, conn s3iface.S3API, d *schema.ResourceDiff) error {
+	if d.Id() == "" || !d.HasChanges(append([]string{"acl", "grant"}, s3BucketGrantHeaderKeys...)...) {
+		return nil
+	}
+
+	acl, _ := d.Get("acl").(string)
+	aclSet := !d.GetRawConfig().GetAttr("acl").IsNull() && acl != s3.BucketCannedACLPrivate
+	grantsSet := d.Get("grant").(*schema.Set).Len() > 0 //nolint:forcetypeassert
+
+	for _, k := range s3BucketGrantHeaderKeys {
+		if _, ok := d.GetOk(k); ok {
+			grantsSet = true
+		}
+	}
+
+	if !aclSet && !grantsSet {
+		return nil
+	}
+
+	out, err := conn.GetBucketOwnershipControlsWithContext(ctx, &s3.GetBucketOwnershipControlsInput{
+		Bucket: aws.String(d.Id()),
+	})
+	if err != nil {
+		log.Printf("[DEBUG] Skipping S3 Bucket (%s) object ownership check: %s", d.Id(), err)
+
+		return nil
+	}
+
+	if out.OwnershipControls == nil {
+		return nil
+	}
+
+	for _, rule := range out.OwnershipControls.Rules {
+		if aws.StringValue(rule.ObjectOwnership) == s3.ObjectOwnershipBucketOwnerEnforced {
+			return fmt.Errorf("S3 Bucket (%s) has BucketOwnerEnforced object ownership, which disables ACLs: "+
+				"remove acl and the grants from the configuration, or change the object ownership of the bucket "+
+				"to BucketOwnerPreferred or ObjectWriter", d.Id())
+		}
+	}
+
+	return nil
+}
+
+// createS3Bucket creates a bucket, retrying for up to timeout while an operation on the bucket is in progress
+// or while the bucket is still reported as existing after its deletion.
+func createS3Bucket(ctx context.Context, conn s3iface.S3API, input *s3.CreateBucketInput, timeout time.Duration) error {
+	bucket := aws.StringValue(input.Bucket)
+
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		log.Printf("[DEBUG] Trying to create new S3 bucket: %q", bucket)
+
+		_, err := conn.CreateBucketWithContext(ctx, input)
+
+		var awsErr awserr.Error
+
+		if errors.As(err, &awsErr) {
+			if awsErr.Code() == "OperationAborted" {
+				log.Printf("[WARN] Got an error while trying to create S3 bucket %s: %s", bucket, err)
+
+				return retry.RetryableError(
+					fmt.Errorf("error creating S3 bucket %s, retrying: %w", bucket, err))
+			}
+
+			// Some backends still report a bucket that was just deleted as existing. HeadBucket tells
+			// this apart from a name taken by another account, which is not retried.
+			if awsErr.Code() == s3.ErrCodeBucketAlreadyExists {
+				if checkErr := checkS3BucketNameAvailable(ctx, conn, bucket); checkErr != nil {
+					return retry.NonRetryableError(checkErr)
+				}
+
+				log.Printf("[WARN] S3 bucket %s reported as existing but not found, retrying: %s", bucket, err)
+
+				return retry.RetryableError(
+					fmt.Errorf("error creating S3 bucket %s, retrying: %w", bucket, err))
+			}
+		}
+
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+
+		return nil
+	})
+
+	if isResourceTimeoutError(err) {
+		_, err = conn.CreateBucketWithContext(ctx, input)
+	}
+
+	return err
+}
+
+// checkS3BucketNameAvailable returns an error if a bucket with the given name already exists,
+// telling apart buckets of the caller's account from buckets of other accounts.
+// Bucket names are global, so a bucket of another account can never be created.
+func checkS3BucketNameAvailable(ctx context.Context, conn s3iface.S3API, bucket string) error {
+	_, err := conn.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+
+	switch {
+	case err == nil:
+		return fmt.Errorf("S3 bucket %q already exists and is owned by you: import it instead of creating it", bucket)
+	case isAWSErrRequestFailureStatusCode(err, http.StatusForbidden):
+		return fmt.Errorf("S3 bucket %q already exists and is owned by another account: choose another name", bucket)
+	case isAWSErrRequestFailureStatusCode(err, http.StatusMovedPermanently):
+		return fmt.Errorf("S3 bucket %q already exists in another region: choose another name", bucket)
+	case isAWSErrRequestFailureStatusCode(err, http.StatusNotFound):
+		log.Printf("[DEBUG] S3 bucket name %q is available", bucket)
+
+		return nil
+	default:
+		return fmt.Errorf("error checking whether S3 bucket %q exists: %w", bucket, err)
+	}
+}
+
+// validateS3BucketName validates an S3 bucket name against the Rabata bucket naming rules of the region.
+// Every Rabata region applies the DNS-compatible rules of S3 general purpose buckets: 3 to 63 lowercase
+// letters, numbers, periods and hyphens, starting and ending with a letter or number, with single periods
+// between labels and not formatted as an IP address. Unlike AWS, Rabata has no legacy bucket names in us-east-1,
+// so uppercase letters and underscores are only accepted with relaxed_bucket_name_validation.
+// A SchemaValidateFunc does not know the provider region, so the plan-time check runs in CustomizeDiff.
+func validateS3BucketName(value string, region string) error {
 	if (len(value) < 3) || (len(value) > 63) { //nolint:mnd
-		return fmt.Errorf("%q must contain from 3 to 63 characters", value)
+		return fmt.Errorf("%q must contain from 3 to 63 characters in region %s", value, region)
 	}
 
 	if !regexp.MustCompile(`^[0-9a-z-.]+$`).MatchString(value) {
-		return fmt.Errorf("only lowercase alphanumeric characters and hyphens allowed in %q", value)
+		return fmt.Errorf("only lowercase alphanumeric characters, hyphens and periods allowed in %q in region %s",
+			value, region)
+	}
+
+	if !regexp.MustCompile(`^[0-9a-z].*[0-9a-z]$`).MatchString(value) {
+		return fmt.Errorf("%q must start and end with a lowercase letter or a number in region %s", value, region)
 	}
 
 	if regexp.MustCompile(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`).MatchString(value) {
 		return fmt.Errorf("%q must not be formatted as an IP address", value)
 	}
 
-	if strings.HasPrefix(value, `.`) {
-		return fmt.Errorf("%q cannot start with a period", value)
+	if strings.Contains(value, `..`) {
+		return fmt.Errorf("%q can be only one period between labels", value)
 	}
 
-	if strings.HasSuffix(value, `.`) {
-		return fmt.Errorf("%q cannot end with a period", value)
+	return nil
+}
+
+// validateS3BucketNameRelaxed validates a legacy, non DNS-compatible S3 bucket name in any region.
+func validateS3BucketNameRelaxed(value string, _ string) error {
+	if (len(value) < 1) || (len(value) > 255) { //nolint:mnd
+		return fmt.Errorf("%q must contain less than 256 characters", value)
 	}
 
-	if strings.Contains(value, `..`) {
-		return fmt.Errorf("%q can be only one period between labels", value)
+	if !regexp.MustCompile(`^[0-9a-zA-Z-._]+$`).MatchString(value) {
+		return fmt.Errorf("only alphanumeric characters, hyphens, periods, and underscores allowed in %q", value)
 	}
 
 	return nil
@@ -528,6 +1408,83 @@ func grantHash(v any) int {
 	return hashcode.String(buf.String())
 }
 
+func expandGrants(bucket string, rawGrants []any) []*s3.Grant {
+	grants := make([]*s3.Grant, 0, len(rawGrants))
+
+	for _, rawGrant := range rawGrants {
+		log.Printf("[DEBUG] S3 bucket: %s, put grant: %#v", bucket, rawGrant)
+		grantMap := rawGrant.(map[string]any) //nolint:forcetypeassert
+
+		for _, rawPermission := range grantMap["permissions"].(*schema.Set).List() { //nolint:forcetypeassert
+			ge := &s3.Grantee{}
+			if i, ok := grantMap["id"].(string); ok && i != "" {
+				ge.SetID(i)
+			}
+
+			if t, ok := grantMap["type"].(string); ok && t != "" {
+				ge.SetType(t)
+			}
+
+			if u, ok := grantMap["uri"].(string); ok && u != "" {
+				ge.SetURI(u)
+			}
+
+			//nolint:forcetypeassert
+			g := &s3.Grant{
+				Grantee:    ge,
+				Permission: aws.String(rawPermission.(string)),
+			}
+			grants = append(grants, g)
+		}
+	}
+
+	return grants
+}
+
+func isSameGrant(a, b *s3.Grant) bool {
+	return aws.StringValue(a.Permission) == aws.StringValue(b.Permission) &&
+		aws.StringValue(a.Grantee.Type) == aws.StringValue(b.Grantee.Type) &&
+		aws.StringValue(a.Grantee.ID) == aws.StringValue(b.Grantee.ID) &&
+		aws.StringValue(a.Grantee.URI) == aws.StringValue(b.Grantee.URI)
+}
+
+// filterConfiguredGrants keeps the grantees of the configured grants and, for each of them,
+// only the configured permissions, so that the grants of the canned ACL do not cause a diff.
+func filterConfiguredGrants(grants, configured []any) []any {
+	filtered := make([]any, 0, len(configured))
+
+	for _, g := range grants {
+		grant := g.(map[string]any) //nolint:forcetypeassert
+
+		for _, c := range configured {
+			configuredGrant := c.(map[string]any) //nolint:forcetypeassert
+			if grant["type"] != configuredGrant["type"] || !isSameGranteeField(grant, configuredGrant, "id") ||
+				!isSameGranteeField(grant, configuredGrant, "uri") {
+				continue
+			}
+
+			permissions := grant["permissions"].(*schema.Set).Intersection( //nolint:forcetypeassert
+				configuredGrant["permissions"].(*schema.Set)) //nolint:forcetypeassert
+			if permissions.Len() > 0 {
+				grant["permissions"] = permissions
+				filtered = append(filtered, grant)
+			}
+
+			break
+		}
+	}
+
+	return filtered
+}
+
+// isSameGranteeField compares an optional grantee field, which flattenGrants leaves out when unset.
+func isSameGranteeField(a, b map[string]any, k string) bool {
+	av, _ := a[k].(string)
+	bv, _ := b[k].(string)
+
+	return av == bv
+}
+
 func flattenGrants(ap *s3.GetBucketAclOutput) []any {
 	// if ACL grants contains bucket owner FULL_CONTROL only - it is default "private" acl
 	if len(ap.Grants) == 1 && aws.StringValue(ap.Grants[0].Grantee.ID) == aws.StringValue(ap.Owner.ID) &&
@@ -0,0 +1,408 @@
+package rabata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	s3BucketAccessPublic  = "public"
+	s3BucketAccessPrivate = "private"
+
+	// s3BucketPublicReadSid identifies the statement managed by rabata_s3_bucket_access in the bucket policy.
+	s3BucketPublicReadSid = "RabataPublicReadGetObject"
+
+	// s3ErrCodeOperationAborted is returned by S3 while a conflicting operation is in progress on the bucket.
+	s3ErrCodeOperationAborted = "OperationAborted"
+)
+
+func resourceRabataS3BucketAccess() *schema.Resource {
+	return &schema.Resource{
+		Description: "Makes the objects of a bucket publicly readable or private. " +
+			"Making a bucket public calls PutBucketOwnershipControls (BucketOwnerPreferred), " +
+			"PutPublicAccessBlock (all settings false) and PutBucketPolicy (s3:GetObject for everyone), " +
+			"in this order. Making it private calls DeleteBucketPolicy, PutPublicAccessBlock " +
+			"(all settings true) and PutBucketOwnershipControls (BucketOwnerPreferred). " +
+			"Destroying the resource calls DeleteBucketPolicy and DeletePublicAccessBlock. " +
+			"The resource manages the whole bucket policy, unless merge_policy is set.",
+
+		CreateContext: resourceRabataS3BucketAccessPut,
+		ReadContext:   resourceRabataS3BucketAccessRead,
+		UpdateContext: resourceRabataS3BucketAccessPut,
+		DeleteContext: resourceRabataS3BucketAccessDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"access": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{s3BucketAccessPublic, s3BucketAccessPrivate}, false),
+			},
+			"merge_policy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Read the bucket policy and only add or remove the statement with the " +
+					"`" + s3BucketPublicReadSid + "` Sid, keeping the other statements, instead of " +
+					"replacing or deleting the whole policy. This lets several configurations manage distinct " +
+					"statements of one policy, as long as every statement has a unique Sid: statements are merged " +
+					"by Sid, and statements without one are kept as they are. The policy is read back after " +
+					"it is put, and the merge is retried if it was concurrently replaced.",
+			},
+		},
+	}
+}
+
+func resourceRabataS3BucketAccessPut(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket, _ := d.Get("bucket").(string)
+	access, _ := d.Get("access").(string)
+	mergePolicy, _ := d.Get("merge_policy").(bool)
+
+	var err error
+	if access == s3BucketAccessPublic {
+		err = makeS3BucketPublic(ctx, awsClient.s3conn, awsClient.partition, bucket, mergePolicy)
+	} else {
+		err = makeS3BucketPrivate(ctx, awsClient.s3conn, bucket, mergePolicy)
+	}
+
+	if err != nil {
+		return diag.Errorf("error making S3 Bucket (%s) %s: %s", bucket, access, err)
+	}
+
+	d.SetId(bucket)
+
+	return resourceRabataS3BucketAccessRead(ctx, d, meta)
+}
+
+func resourceRabataS3BucketAccessRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	public, err := isS3BucketPublic(ctx, s3conn, d.Id())
+	if isAWSErr(err, s3.ErrCodeNoSuchBucket, "") {
+		log.Printf("[WARN] S3 Bucket (%s) not found, removing from state", d.Id())
+		d.SetId("")
+
+		return nil
+	}
+
+	if err != nil {
+		return diag.Errorf("error reading S3 Bucket (%s) access: %s", d.Id(), err)
+	}
+
+	access := s3BucketAccessPrivate
+	if public {
+		access = s3BucketAccessPublic
+	}
+
+	d.Set("bucket", d.Id()) //nolint:errcheck
+	d.Set("access", access) //nolint:errcheck
+
+	return nil
+}
+
+func resourceRabataS3BucketAccessDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	mergePolicy, _ := d.Get("merge_policy").(bool)
+
+	err := deleteS3BucketPublicReadPolicy(ctx, awsClient.s3conn, d.Id(), mergePolicy)
+	if isAWSErr(err, s3.ErrCodeNoSuchBucket, "") {
+		return nil
+	}
+
+	if err != nil {
+		return diag.Errorf("error deleting S3 Bucket (%s) policy: %s", d.Id(), err)
+	}
+
+	log.Printf("[DEBUG] Deleting S3 Bucket (%s) public access block", d.Id())
+
+	_, err = awsClient.s3conn.DeletePublicAccessBlockWithContext(ctx, &s3.DeletePublicAccessBlockInput{
+		Bucket: aws.String(d.Id()),
+	})
+	if err != nil && !isAWSErr(err, "NoSuchPublicAccessBlockConfiguration", "") {
+		return diag.Errorf("error deleting S3 Bucket (%s) public access block: %s", d.Id(), err)
+	}
+
+	return nil
+}
+
+// makeS3BucketPublic allows anyone to read the objects of the bucket.
+// The public access block has to be lifted before the policy is put,
+// as BlockPublicPolicy rejects public policies with AccessDenied.
+// If mergePolicy is true, the public read statement is merged into the existing policy.
+func makeS3BucketPublic(ctx context.Context, conn s3iface.S3API, partition, bucket string, mergePolicy bool) error {
+	if err := putS3BucketOwnershipPreferred(ctx, conn, bucket); err != nil {
+		return err
+	}
+
+	if err := putS3BucketPublicAccessBlock(ctx, conn, bucket, false); err != nil {
+		return err
+	}
+
+	statement := s3BucketPublicReadStatement(partition, bucket)
+
+	if mergePolicy {
+		return mergeS3BucketPolicyStatement(ctx, conn, bucket, s3BucketPublicReadSid, statement)
+	}
+
+	return putS3BucketPolicyDocument(ctx, conn, bucket, &s3PolicyDocument{
+		Version:    s3PolicyDocumentVersion,
+		Statements: []*s3PolicyStatement{statement},
+	})
+}
+
+// makeS3BucketPrivate removes the public policy before blocking public access again.
+// If mergePolicy is true, only the public read statement is removed from the policy.
+func makeS3BucketPrivate(ctx context.Context, conn s3iface.S3API, bucket string, mergePolicy bool) error {
+	if err := deleteS3BucketPublicReadPolicy(ctx, conn, bucket, mergePolicy); err != nil {
+		return fmt.Errorf("error deleting policy: %w", err)
+	}
+
+	if err := putS3BucketPublicAccessBlock(ctx, conn, bucket, true); err != nil {
+		return err
+	}
+
+	return putS3BucketOwnershipPreferred(ctx, conn, bucket)
+}
+
+// putS3BucketOwnershipPreferred makes the bucket owner own new objects while keeping ACLs enabled.
+// BucketOwnerEnforced would disable ACLs, and rabata_s3_bucket_object puts a canned ACL
+// with every object, which would then fail with AccessControlListNotSupported.
+func putS3BucketOwnershipPreferred(ctx context.Context, conn s3iface.S3API, bucket string) error {
+	log.Printf("[DEBUG] Putting S3 Bucket (%s) ownership controls", bucket)
+
+	_, err := conn.PutBucketOwnershipControlsWithContext(ctx, &s3.PutBucketOwnershipControlsInput{
+		Bucket: aws.String(bucket),
+		OwnershipControls: &s3.OwnershipControls{
+			Rules: []*s3.OwnershipControlsRule{
+				{ObjectOwnership: aws.String(s3.ObjectOwnershipBucketOwnerPreferred)},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error putting ownership controls: %w", err)
+	}
+
+	return nil
+}
+
+func putS3BucketPublicAccessBlock(ctx context.Context, conn s3iface.S3API, bucket string, block bool) error {
+	log.Printf("[DEBUG] Putting S3 Bucket (%s) public access block: %t", bucket, block)
+
+	_, err := conn.PutPublicAccessBlockWithContext(ctx, &s3.PutPublicAccessBlockInput{
+		Bucket: aws.String(bucket),
+		PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(block),
+			BlockPublicPolicy:     aws.Bool(block),
+			IgnorePublicAcls:      aws.Bool(block),
+			RestrictPublicBuckets: aws.Bool(block),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error putting public access block: %w", err)
+	}
+
+	return nil
+}
+
+// isS3BucketPublic returns true if the bucket policy contains the public read statement
+// and public policies are not restricted by the public access block.
+func isS3BucketPublic(ctx context.Context, conn s3iface.S3API, bucket string) (bool, error) {
+	policy, err := getS3BucketPolicyDocument(ctx, conn, bucket)
+	if err != nil {
+		return false, err
+	}
+
+	hasPublicRead := false
+
+	for _, statement := range policy.Statements {
+		if statement.Sid == s3BucketPublicReadSid && statement.Effect == "Allow" {
+			hasPublicRead = true
+		}
+	}
+
+	if !hasPublicRead {
+		return false, nil
+	}
+
+	blockOutput, err := conn.GetPublicAccessBlockWithContext(ctx, &s3.GetPublicAccessBlockInput{
+		Bucket: aws.String(bucket),
+	})
+	if isAWSErr(err, "NoSuchPublicAccessBlockConfiguration", "") {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	block := blockOutput.PublicAccessBlockConfiguration
+	if block == nil {
+		return true, nil
+	}
+
+	return !aws.BoolValue(block.BlockPublicPolicy) && !aws.BoolValue(block.RestrictPublicBuckets), nil
+}
+
+func s3BucketPublicReadStatement(partition, bucket string) *s3PolicyStatement {
+	return &s3PolicyStatement{
+		Sid:        s3BucketPublicReadSid,
+		Effect:     "Allow",
+		Principals: "*",
+		Actions:    "s3:GetObject",
+		Resources: arn.ARN{
+			Partition: partition,
+			Service:   "s3",
+			Resource:  bucket + "/*",
+		}.String(),
+	}
+}
+
+// deleteS3BucketPublicReadPolicy deletes the bucket policy,
+// or only its public read statement if mergePolicy is true.
+func deleteS3BucketPublicReadPolicy(ctx context.Context, conn s3iface.S3API, bucket string, mergePolicy bool) error {
+	if mergePolicy {
+		return mergeS3BucketPolicyStatement(ctx, conn, bucket, s3BucketPublicReadSid, nil)
+	}
+
+	log.Printf("[DEBUG] Deleting S3 Bucket (%s) policy", bucket)
+
+	_, err := conn.DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+
+	return err
+}
+
+// mergeS3BucketPolicyStatement replaces the statement with the given Sid in the bucket policy,
+// or appends it, keeping the statements with other Sids. A nil statement removes the Sid,
+// and the policy is deleted once no statement is left.
+// PutBucketPolicy has no precondition, so the policy is read back after it is put,
+// and the read-modify-write is retried if a concurrent writer replaced it in between.
+func mergeS3BucketPolicyStatement(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket, sid string,
+	statement *s3PolicyStatement,
+) error {
+	hasSid := func(s *s3PolicyStatement) bool {
+		return s.Sid == sid
+	}
+
+	_, err := retryOnAWSCode(ctx, s3ErrCodeOperationAborted, func() (any, error) {
+		policy, err := getS3BucketPolicyDocument(ctx, conn, bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		policy.Statements = slices.DeleteFunc(policy.Statements, hasSid)
+		if statement != nil {
+			policy.Statements = append(policy.Statements, statement)
+		}
+
+		if len(policy.Statements) == 0 {
+			log.Printf("[DEBUG] Deleting S3 Bucket (%s) policy, no statement left", bucket)
+
+			_, err := conn.DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{
+				Bucket: aws.String(bucket),
+			})
+
+			return nil, err
+		}
+
+		if err := putS3BucketPolicyDocument(ctx, conn, bucket, policy); err != nil {
+			return nil, err
+		}
+
+		policy, err = getS3BucketPolicyDocument(ctx, conn, bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.ContainsFunc(policy.Statements, hasSid) != (statement != nil) {
+			return nil, awserr.New(s3ErrCodeOperationAborted,
+				fmt.Sprintf("statement %s was overwritten by a concurrent policy update", sid), nil)
+		}
+
+		return nil, nil //nolint:nilnil
+	})
+
+	return err
+}
+
+// getS3BucketPolicyDocument returns the bucket policy, or an empty one if the bucket has none.
+func getS3BucketPolicyDocument(ctx context.Context, conn s3iface.S3API, bucket string) (*s3PolicyDocument, error) {
+	output, err := conn.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	if isAWSErr(err, "NoSuchBucketPolicy", "") {
+		return &s3PolicyDocument{Version: s3PolicyDocumentVersion}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &s3PolicyDocument{}
+	if err := json.Unmarshal([]byte(aws.StringValue(output.Policy)), policy); err != nil {
+		return nil, fmt.Errorf("error parsing policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func putS3BucketPolicyDocument(ctx context.Context, conn s3iface.S3API, bucket string, policy *s3PolicyDocument) error {
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("error marshaling policy: %w", err)
+	}
+
+	log.Printf("[DEBUG] Putting S3 Bucket (%s) policy: %s", bucket, b)
+
+	_, err = conn.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(string(b)),
+	})
+	if err != nil {
+		return fmt.Errorf("error putting policy: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,531 @@
+package rabata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const s3LifecycleRuleUpdateTimeout = 2 * time.Minute
+
+// s3LifecycleExpirationKeys are the expiration attributes, S3 requires exactly one of them.
+var s3LifecycleExpirationKeys = []string{
+	"expiration.0.date",
+	"expiration.0.days",
+	"expiration.0.expired_object_delete_marker",
+}
+
+func resourceRabataS3BucketLifecycleRule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRabataS3BucketLifecycleRuleCreate,
+		ReadContext:   resourceRabataS3BucketLifecycleRuleRead,
+		UpdateContext: resourceRabataS3BucketLifecycleRuleUpdate,
+		DeleteContext: resourceRabataS3BucketLifecycleRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"rule_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringLenBetween(1, 255), //nolint:mnd
+				Description: "The ID of the rule in the lifecycle configuration of the bucket. " +
+					"It is not named `id`, which Terraform reserves for the resource ID, `<bucket>/<rule_id>`.",
+			},
+
+			"prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"abort_incomplete_multipart_upload_days": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"expiration": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"date": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsRFC3339Time,
+							ExactlyOneOf: s3LifecycleExpirationKeys,
+						},
+						"days": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+							ExactlyOneOf: s3LifecycleExpirationKeys,
+						},
+						"expired_object_delete_marker": {
+							Type:         schema.TypeBool,
+							Optional:     true,
+							ExactlyOneOf: s3LifecycleExpirationKeys,
+						},
+					},
+				},
+			},
+
+			"noncurrent_version_expiration": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"days": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+					},
+				},
+			},
+
+			"transition": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"date": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsRFC3339Time,
+						},
+						"days": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"storage_class": {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								s3.TransitionStorageClassGlacier,
+								s3.TransitionStorageClassStandardIa,
+								s3.TransitionStorageClassOnezoneIa,
+								s3.TransitionStorageClassIntelligentTiering,
+								s3.TransitionStorageClassDeepArchive,
+							}, false),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceRabataS3BucketLifecycleRuleCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
+	s3conn := awsClient.s3conn
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket := d.Get("bucket").(string)  //nolint:forcetypeassert
+	ruleID := d.Get("rule_id").(string) //nolint:forcetypeassert
+
+	rule, err := expandS3LifecycleRule(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = updateS3BucketLifecycleRule(ctx, s3conn, bucket, ruleID, rule)
+	if err != nil {
+		return diag.Errorf("error creating S3 Bucket (%s) lifecycle rule (%s): %s", bucket, ruleID, err)
+	}
+
+	d.SetId(bucket + "/" + ruleID)
+
+	return resourceRabataS3BucketLifecycleRuleRead(ctx, d, meta)
+}
+
+func resourceRabataS3BucketLifecycleRuleRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket, ruleID, err := parseS3BucketLifecycleRuleID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rules, err := getS3BucketLifecycleRules(ctx, s3conn, bucket)
+	if isAWSErr(err, s3.ErrCodeNoSuchBucket, "") {
+		log.Printf("[WARN] S3 Bucket (%s) not found, removing lifecycle rule (%s) from state", bucket, ruleID)
+		d.SetId("")
+
+		return nil
+	}
+
+	if err != nil {
+		return diag.Errorf("error reading S3 Bucket (%s) lifecycle configuration: %s", bucket, err)
+	}
+
+	rule := findS3LifecycleRule(rules, ruleID)
+	if rule == nil {
+		log.Printf("[WARN] S3 Bucket (%s) lifecycle rule (%s) not found, removing from state", bucket, ruleID)
+		d.SetId("")
+
+		return nil
+	}
+
+	d.Set("bucket", bucket)  //nolint:errcheck
+	d.Set("rule_id", ruleID) //nolint:errcheck
+
+	prefix := aws.StringValue(rule.Prefix)
+	if rule.Filter != nil && rule.Filter.Prefix != nil {
+		prefix = aws.StringValue(rule.Filter.Prefix)
+	}
+
+	d.Set("prefix", prefix)                                                      //nolint:errcheck
+	d.Set("enabled", aws.StringValue(rule.Status) == s3.ExpirationStatusEnabled) //nolint:errcheck
+
+	if rule.AbortIncompleteMultipartUpload != nil {
+		//nolint:errcheck
+		d.Set("abort_incomplete_multipart_upload_days",
+			aws.Int64Value(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation))
+	} else {
+		d.Set("abort_incomplete_multipart_upload_days", 0) //nolint:errcheck
+	}
+
+	if err := d.Set("expiration", flattenS3LifecycleExpiration(rule.Expiration)); err != nil {
+		return diag.Errorf("error setting expiration: %s", err)
+	}
+
+	noncurrentVersionExpiration := flattenS3NoncurrentVersionExpiration(rule.NoncurrentVersionExpiration)
+	if err := d.Set("noncurrent_version_expiration", noncurrentVersionExpiration); err != nil {
+		return diag.Errorf("error setting noncurrent_version_expiration: %s", err)
+	}
+
+	if err := d.Set("transition", flattenS3LifecycleTransitions(rule.Transitions)); err != nil {
+		return diag.Errorf("error setting transition: %s", err)
+	}
+
+	return nil
+}
+
+func resourceRabataS3BucketLifecycleRuleUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
+	s3conn := awsClient.s3conn
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket := d.Get("bucket").(string)  //nolint:forcetypeassert
+	ruleID := d.Get("rule_id").(string) //nolint:forcetypeassert
+
+	rule, err := expandS3LifecycleRule(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = updateS3BucketLifecycleRule(ctx, s3conn, bucket, ruleID, rule)
+	if err != nil {
+		return diag.Errorf("error updating S3 Bucket (%s) lifecycle rule (%s): %s", bucket, ruleID, err)
+	}
+
+	return resourceRabataS3BucketLifecycleRuleRead(ctx, d, meta)
+}
+
+func resourceRabataS3BucketLifecycleRuleDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	s3conn := awsClient.s3conn
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket, diags := requiredResourceDataString(d, "bucket")
+	if diags.HasError() {
+		return diags
+	}
+
+	ruleID, diags := requiredResourceDataString(d, "rule_id")
+	if diags.HasError() {
+		return diags
+	}
+
+	err := updateS3BucketLifecycleRule(ctx, s3conn, bucket, ruleID, nil)
+	if isAWSErr(err, s3.ErrCodeNoSuchBucket, "") {
+		return nil
+	}
+
+	if err != nil {
+		return diag.Errorf("error deleting S3 Bucket (%s) lifecycle rule (%s): %s", bucket, ruleID, err)
+	}
+
+	return nil
+}
+
+// updateS3BucketLifecycleRule replaces the lifecycle rule with the given ID in the
+// bucket lifecycle configuration, keeping all other rules as they are.
+// If rule is nil, the rule is removed from the configuration.
+//
+// S3 has no conditional writes for lifecycle configuration, so the configuration
+// is read back after each write and the update is retried if a concurrent
+// writer has overwritten it.
+func updateS3BucketLifecycleRule(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket, ruleID string,
+	rule *s3.LifecycleRule,
+) error {
+	err := retry.RetryContext(ctx, s3LifecycleRuleUpdateTimeout, func() *retry.RetryError {
+		rules, err := getS3BucketLifecycleRules(ctx, conn, bucket)
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+
+		newRules := make([]*s3.LifecycleRule, 0, len(rules)+1)
+
+		for _, r := range rules {
+			if aws.StringValue(r.ID) != ruleID {
+				newRules = append(newRules, r)
+			}
+		}
+
+		if rule != nil {
+			newRules = append(newRules, rule)
+		}
+
+		if err := putS3BucketLifecycleRules(ctx, conn, bucket, newRules); err != nil {
+			if isAWSErr(err, "OperationAborted", "") {
+				return retry.RetryableError(err)
+			}
+
+			return retry.NonRetryableError(err)
+		}
+
+		rules, err = getS3BucketLifecycleRules(ctx, conn, bucket)
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+
+		if found := findS3LifecycleRule(rules, ruleID) != nil; found != (rule != nil) {
+			log.Printf("[DEBUG] S3 Bucket (%s) lifecycle rule (%s) was overwritten by a concurrent update", bucket, ruleID)
+
+			return retry.RetryableError(fmt.Errorf("lifecycle rule (%s) was overwritten by a concurrent update", ruleID))
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func getS3BucketLifecycleRules(ctx context.Context, conn s3iface.S3API, bucket string) ([]*s3.LifecycleRule, error) {
+	out, err := conn.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if isAWSErr(err, "NoSuchLifecycleConfiguration", "") {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Rules, nil
+}
+
+func putS3BucketLifecycleRules(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket string,
+	rules []*s3.LifecycleRule,
+) error {
+	if len(rules) == 0 {
+		log.Printf("[DEBUG] S3 Bucket (%s) has no lifecycle rules left, deleting lifecycle configuration", bucket)
+
+		_, err := conn.DeleteBucketLifecycleWithContext(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(bucket),
+		})
+
+		return err
+	}
+
+	input := &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{
+			Rules: rules,
+		},
+	}
+
+	log.Printf("[DEBUG] S3 put bucket lifecycle configuration: %s", input)
+
+	_, err := conn.PutBucketLifecycleConfigurationWithContext(ctx, input)
+
+	return err
+}
+
+func findS3LifecycleRule(rules []*s3.LifecycleRule, ruleID string) *s3.LifecycleRule {
+	for _, rule := range rules {
+		if aws.StringValue(rule.ID) == ruleID {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+func parseS3BucketLifecycleRuleID(id string) (string, string, error) {
+	bucket, ruleID, ok := strings.Cut(id, "/")
+	if !ok || bucket == "" || ruleID == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected BUCKET/RULE_ID", id)
+	}
+
+	return bucket, ruleID, nil
+}
+
+func expandS3LifecycleRule(d *schema.ResourceData) (*s3.LifecycleRule, error) {
+	//nolint:forcetypeassert
+	rule := &s3.LifecycleRule{
+		ID: aws.String(d.Get("rule_id").(string)),
+		Filter: &s3.LifecycleRuleFilter{
+			Prefix: aws.String(d.Get("prefix").(string)),
+		},
+		Status: aws.String(s3.ExpirationStatusDisabled),
+	}
+
+	if d.Get("enabled").(bool) { //nolint:forcetypeassert
+		rule.Status = aws.String(s3.ExpirationStatusEnabled)
+	}
+
+	if v, ok := d.GetOk("abort_incomplete_multipart_upload_days"); ok {
+		rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int64(int64(v.(int))), //nolint:forcetypeassert
+		}
+	}
+
+	if v, ok := d.GetOk("expiration"); ok {
+		e := v.([]any)[0].(map[string]any) //nolint:forcetypeassert
+		expiration := &s3.LifecycleExpiration{}
+
+		if date, ok := e["date"].(string); ok && date != "" {
+			t, err := time.Parse(time.RFC3339, date)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing expiration date (%s): %w", date, err)
+			}
+
+			expiration.Date = aws.Time(t)
+		} else if days, ok := e["days"].(int); ok && days > 0 {
+			expiration.Days = aws.Int64(int64(days))
+		} else if marker, ok := e["expired_object_delete_marker"].(bool); ok && marker {
+			expiration.ExpiredObjectDeleteMarker = aws.Bool(marker)
+		} else {
+			return nil, errors.New("expiration requires date, days or expired_object_delete_marker = true")
+		}
+
+		rule.Expiration = expiration
+	}
+
+	if v, ok := d.GetOk("noncurrent_version_expiration"); ok {
+		e := v.([]any)[0].(map[string]any) //nolint:forcetypeassert
+		rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int64(int64(e["days"].(int))), //nolint:forcetypeassert
+		}
+	}
+
+	for _, v := range d.Get("transition").([]any) { //nolint:forcetypeassert
+		t := v.(map[string]any) //nolint:forcetypeassert
+		transition := &s3.Transition{
+			StorageClass: aws.String(t["storage_class"].(string)), //nolint:forcetypeassert
+		}
+
+		if date, ok := t["date"].(string); ok && date != "" {
+			parsed, err := time.Parse(time.RFC3339, date)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing transition date (%s): %w", date, err)
+			}
+
+			transition.Date = aws.Time(parsed)
+		} else if days, ok := t["days"].(int); ok {
+			transition.Days = aws.Int64(int64(days))
+		}
+
+		rule.Transitions = append(rule.Transitions, transition)
+	}
+
+	return rule, nil
+}
+
+func flattenS3LifecycleExpiration(expiration *s3.LifecycleExpiration) []any {
+	if expiration == nil {
+		return nil
+	}
+
+	m := map[string]any{
+		"days":                         int(aws.Int64Value(expiration.Days)),
+		"expired_object_delete_marker": aws.BoolValue(expiration.ExpiredObjectDeleteMarker),
+	}
+
+	if expiration.Date != nil {
+		m["date"] = expiration.Date.UTC().Format(time.RFC3339)
+	}
+
+	return []any{m}
+}
+
+func flattenS3NoncurrentVersionExpiration(expiration *s3.NoncurrentVersionExpiration) []any {
+	if expiration == nil {
+		return nil
+	}
+
+	return []any{map[string]any{
+		"days": int(aws.Int64Value(expiration.NoncurrentDays)),
+	}}
+}
+
+func flattenS3LifecycleTransitions(transitions []*s3.Transition) []any {
+	results := make([]any, 0, len(transitions))
+
+	for _, transition := range transitions {
+		m := map[string]any{
+			"days":          int(aws.Int64Value(transition.Days)),
+			"storage_class": aws.StringValue(transition.StorageClass),
+		}
+
+		if transition.Date != nil {
+			m["date"] = transition.Date.UTC().Format(time.RFC3339)
+		}
+
+		results = append(results, m)
+	}
+
+	return results
+}
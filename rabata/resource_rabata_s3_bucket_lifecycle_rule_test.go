@@ -0,0 +1,56 @@
+package rabata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceRabataS3BucketLifecycleRuleExpirationValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		expiration map[string]any
+		expectErr  bool
+	}{
+		{name: "days", expiration: map[string]any{"days": 30}},
+		{name: "date", expiration: map[string]any{"date": "2030-01-01T00:00:00Z"}},
+		{name: "expired object delete marker", expiration: map[string]any{"expired_object_delete_marker": true}},
+		{name: "empty", expiration: map[string]any{}, expectErr: true},
+		{name: "zero days", expiration: map[string]any{"days": 0}, expectErr: true},
+		{name: "days and date", expiration: map[string]any{"days": 30, "date": "2030-01-01T00:00:00Z"}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			diags := resourceRabataS3BucketLifecycleRule().Validate(terraform.NewResourceConfigRaw(map[string]any{
+				"bucket":     "tf-test-bucket",
+				"rule_id":    "expire",
+				"expiration": []any{tc.expiration},
+			}))
+			if diags.HasError() != tc.expectErr {
+				t.Errorf("expected error %t, got %v", tc.expectErr, diags)
+			}
+		})
+	}
+}
+
+func TestResourceRabataS3BucketLifecycleRuleDeleteInvalid(t *testing.T) {
+	t.Parallel()
+
+	d := resourceRabataS3BucketLifecycleRule().TestResourceData()
+	d.SetId("tf-test/")
+
+	if diags := resourceRabataS3BucketLifecycleRuleDelete(context.Background(), d, nil); !diags.HasError() {
+		t.Error("expected an error without provider configuration, got none")
+	}
+
+	// The client has no S3 connection, the delete must fail before sending any request.
+	if diags := resourceRabataS3BucketLifecycleRuleDelete(context.Background(), d, &AWSClient{}); !diags.HasError() {
+		t.Error("expected an error without bucket and rule_id, got none")
+	}
+}
@@ -0,0 +1,203 @@
+package rabata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceRabataS3BucketMultipartCleanup aborts the incomplete multipart uploads of a bucket
+// when it is created. Changing triggers re-runs the cleanup, destroying the resource does nothing.
+func resourceRabataS3BucketMultipartCleanup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRabataS3BucketMultipartCleanupCreate,
+		ReadContext:   schema.NoopContext,
+		DeleteContext: resourceRabataS3BucketMultipartCleanupDelete,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"older_than": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "168h",
+				ValidateFunc: validateS3MultipartCleanupOlderThan,
+			},
+
+			"max_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      4,                            //nolint:mnd
+				ValidateFunc: validation.IntBetween(1, 16), //nolint:mnd
+			},
+
+			"triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"aborted_uploads": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceRabataS3BucketMultipartCleanupCreate(
+	ctx context.Context,
+	d *schema.ResourceData,
+	meta any,
+) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	conn := awsClient.s3conn
+	bucket, _ := d.Get("bucket").(string)
+	prefix, _ := d.Get("prefix").(string)
+	olderThan, _ := time.ParseDuration(d.Get("older_than").(string)) //nolint:forcetypeassert
+	maxConcurrency, _ := d.Get("max_concurrency").(int)
+
+	uploads, err := listS3MultipartUploads(ctx, conn, bucket, prefix)
+	if err != nil {
+		return diag.Errorf("error listing S3 Bucket (%s) multipart uploads: %s", bucket, err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	stale := make([]*s3.MultipartUpload, 0, len(uploads))
+
+	for _, upload := range uploads {
+		if aws.TimeValue(upload.Initiated).Before(cutoff) {
+			stale = append(stale, upload)
+		}
+	}
+
+	log.Printf("[INFO] Aborting %d of %d S3 Bucket (%s) multipart uploads initiated before %s",
+		len(stale), len(uploads), bucket, cutoff.Format(time.RFC3339))
+
+	if err := abortS3MultipartUploads(ctx, conn, bucket, stale, maxConcurrency); err != nil {
+		return diag.Errorf("error aborting S3 Bucket (%s) multipart uploads: %s", bucket, err)
+	}
+
+	d.SetId(id.UniqueId())
+	d.Set("aborted_uploads", len(stale)) //nolint:errcheck
+
+	return nil
+}
+
+func resourceRabataS3BucketMultipartCleanupDelete(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	log.Printf("[INFO] Removing S3 Bucket multipart cleanup (%s) from state", d.Id())
+
+	return nil
+}
+
+// listS3MultipartUploads pages through all the in-progress multipart uploads under prefix.
+func listS3MultipartUploads(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket, prefix string,
+) ([]*s3.MultipartUpload, error) {
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucket),
+	}
+
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	var uploads []*s3.MultipartUpload
+
+	err := conn.ListMultipartUploadsPagesWithContext(ctx, input, func(page *s3.ListMultipartUploadsOutput, _ bool) bool {
+		uploads = append(uploads, page.Uploads...)
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+// abortS3MultipartUploads aborts uploads, running at most maxConcurrency requests at a time.
+// Uploads completed or aborted in the meantime are ignored.
+func abortS3MultipartUploads(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket string,
+	uploads []*s3.MultipartUpload,
+	maxConcurrency int,
+) error {
+	errs := make([]error, len(uploads))
+	sem := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+
+	for i, upload := range uploads {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			log.Printf("[DEBUG] Aborting S3 Bucket (%s) Object (%s) multipart upload (%s)",
+				bucket, aws.StringValue(upload.Key), aws.StringValue(upload.UploadId))
+
+			_, err := conn.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(bucket),
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+			if err != nil && !isAWSErr(err, s3.ErrCodeNoSuchUpload, "") {
+				errs[i] = fmt.Errorf("%s (%s): %w", aws.StringValue(upload.Key), aws.StringValue(upload.UploadId), err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func validateS3MultipartCleanupOlderThan(v any, k string) ([]string, []error) {
+	d, err := time.ParseDuration(v.(string)) //nolint:forcetypeassert
+	if err != nil {
+		return nil, []error{fmt.Errorf("%q must be a duration, e.g. 168h: %w", k, err)}
+	}
+
+	if d < 0 {
+		return nil, []error{fmt.Errorf("%q must not be negative, got %s", k, d)}
+	}
+
+	return nil, nil
+}
@@ -2,26 +2,78 @@ package rabata
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"io/fs"
 	"log"
+	"maps"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/mitchellh/go-homedir"
 )
 
+const s3ObjectDeleteTimeout = 2 * time.Minute
+
+// defaultS3ObjectContentType is the content type of objects created from inline content.
+const defaultS3ObjectContentType = "text/plain; charset=utf-8"
+
+// s3ObjectComputedAttributes are the attributes read from the object headers that computed_attributes can select.
+// The etag and version_id are always read, they are needed to detect changes and to delete the object.
+var s3ObjectComputedAttributes = []string{
+	"cache_control",
+	"content_disposition",
+	"content_encoding",
+	"content_language",
+	"content_type",
+	"metadata",
+	"object_lock_legal_hold_status",
+	"storage_class",
+}
+
+// s3ObjectContentEncodingGzip is the Content-Encoding of objects uploaded with gzip.
+const s3ObjectContentEncodingGzip = "gzip"
+
+const (
+	s3ObjectETagVerificationWarn  = "warn"
+	s3ObjectETagVerificationError = "error"
+)
+
+const (
+	// s3ObjectExpireAfterTagKey is the object tag holding the number of days of expire_after, e.g. 30d.
+	s3ObjectExpireAfterTagKey = "expire-after"
+	// s3ObjectExpireAfterUnit is the unit of expire_after, lifecycle rules count expiration in days.
+	s3ObjectExpireAfterUnit = 24 * time.Hour
+	// s3ObjectDeletionMinWait is the shortest wait_for_deletion poll window, used when
+	// the delete itself used up most of the delete timeout.
+	s3ObjectDeletionMinWait = 30 * time.Second
+)
+
 func resourceRabataS3BucketObject() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceRabataS3BucketObjectCreate,
@@ -31,6 +83,10 @@ func resourceRabataS3BucketObject() *schema.Resource {
 
 		CustomizeDiff: resourceRabataS3BucketObjectCustomizeDiff,
 
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(s3ObjectDeleteTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"bucket": {
 				Type:         schema.TypeString,
@@ -61,14 +117,62 @@ func resourceRabataS3BucketObject() *schema.Resource {
 				}, false),
 			},
 
+			"grant_bucket_owner_full_control": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ConflictsWith: []string{
+					"acl", "grant_full_control", "grant_read", "grant_read_acp", "grant_write_acp",
+				},
+				Description: "Put the object with the `bucket-owner-full-control` canned ACL, so that the owner of " +
+					"a bucket owned by another account keeps full control of the object.",
+			},
+
+			"grant_full_control": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"acl"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
+			"grant_read": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"acl"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
+			"grant_read_acp": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"acl"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
+			"grant_write_acp": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"acl"},
+				ValidateFunc:  validateS3GrantHeader,
+			},
+
 			"cache_control": {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
 
 			"content_disposition": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"attachment_filename"},
+				DiffSuppressFunc: suppressS3ObjectContentDispositionDiff,
+			},
+
+			"attachment_filename": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"content_disposition"},
+				ValidateFunc:  validation.NoZeroValues,
 			},
 
 			"content_encoding": {
@@ -79,6 +183,8 @@ func resourceRabataS3BucketObject() *schema.Resource {
 			"content_language": {
 				Type:     schema.TypeString,
 				Optional: true,
+				// Some backends return an empty Content-Language header for objects uploaded without one.
+				DiffSuppressFunc: suppressUnsetStringDiff,
 			},
 
 			"metadata": {
@@ -88,10 +194,27 @@ func resourceRabataS3BucketObject() *schema.Resource {
 				Elem:         &schema.Schema{Type: schema.TypeString},
 			},
 
+			"metadata_json": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateS3MetadataJSON,
+			},
+
 			"content_type": {
 				Type:     schema.TypeString,
 				Optional: true,
 				Computed: true,
+				Description: "Defaults to the type of the source file extension, or else to the type sniffed " +
+					"from its first 512 bytes, `application/json` for a file holding a JSON object or array.",
+			},
+
+			"source_metadata_sidecar": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"content", "content_base64"},
+				Description: "Apply the JSON object of string values in the `<source>.meta.json` file, if it exists, " +
+					"as object metadata. Keys are lowercased, and keys set in metadata or metadata_json take precedence.",
 			},
 
 			"source": {
@@ -112,6 +235,45 @@ func resourceRabataS3BucketObject() *schema.Resource {
 				ConflictsWith: []string{"source", "content"},
 			},
 
+			"content_length": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"gzip": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"content_encoding", "content_length"},
+				Description: "Compress the content with gzip before uploading it and set `Content-Encoding: gzip`. " +
+					"The `etag` of the object is the MD5 of the compressed content.",
+			},
+
+			"object_lock_legal_hold_status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(s3.ObjectLockLegalHoldStatus_Values(), false),
+			},
+
+			"expire_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateS3ObjectExpireAfter,
+				Description: "A duration in whole days such as `720h`, at least `24h`. The object is tagged with " +
+					"`expire-after` set to the number of days, e.g. `30d`. The tag does not expire the object by " +
+					"itself: pair it with a lifecycle rule that filters on the tag with the same value and " +
+					"expires objects after as many days, e.g. `expire-after` = `30d` with `days = 30`. " +
+					"Each distinct `expire_after` needs its own lifecycle rule.",
+			},
+
+			"expires_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "The last modification time of the object plus `expire_after` in RFC 3339 format, " +
+					"when `expire_after` is set. The lifecycle rule expires the object at the earliest at this time.",
+			},
+
 			"storage_class": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -127,13 +289,21 @@ func resourceRabataS3BucketObject() *schema.Resource {
 				}, false),
 			},
 
+			"sse_kms_encryption_context": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ValidateFunc: validateBase64EncodedJSON,
+			},
+
 			"etag": {
 				Type: schema.TypeString,
 				// This will conflict with SSE-C and multi-part upload
 				// if/when it's actually implemented. The Etag then won't match raw-file MD5.
 				// See http://docs.aws.amazon.com/AmazonS3/latest/API/RESTCommonResponseHeaders.html
-				Optional: true,
-				Computed: true,
+				Optional:  true,
+				Computed:  true,
+				StateFunc: normalizeS3ETag,
 			},
 
 			"version_id": {
@@ -146,14 +316,83 @@ func resourceRabataS3BucketObject() *schema.Resource {
 				Optional: true,
 				Default:  false,
 			},
+
+			"wait_for_deletion": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "After deleting the object, poll it with HeadObject until it is not found, within the " +
+					"delete timeout, so that resources depending on the deletion do not see the old object " +
+					"on eventually consistent backends.",
+			},
+
+			"etag_verification": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					s3ObjectETagVerificationWarn,
+					s3ObjectETagVerificationError,
+				}, false),
+			},
+
+			"checksum_sha256": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Send the SHA-256 of the body in the `x-amz-checksum-sha256` header, so that the upload " +
+					"is rejected if the received body does not match, and compare it with the checksum returned " +
+					"by the backend, deleting the object on mismatch. Multipart uploads are not verified.",
+			},
+
+			"track_acl": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"computed_attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(s3ObjectComputedAttributes, false),
+				},
+				Description: "The attributes read back from the object, by default all of them. The other " +
+					"attributes keep their configured values in the state and changes made outside of " +
+					"Terraform are not detected, which keeps the state small when managing many objects.",
+			},
+
+			"request_payer": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(s3.RequestPayer_Values(), false),
+				Description: "Set to `requester` to access a requester pays bucket. The requests, and the " +
+					"data transferred by them, are then charged to the account of the provider credentials " +
+					"instead of the bucket owner.",
+			},
+
+			"expected_bucket_owner": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
 		},
 	}
 }
 
 func resourceRabataS3BucketObjectPut(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
+	s3conn := awsClient.s3conn
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
 
-	var body io.ReadSeeker
+	var (
+		body              io.ReadSeeker
+		sourceContentType string
+	)
 
 	if v, ok := d.GetOk("source"); ok { //nolint:nestif
 		source := v.(string) //nolint:forcetypeassert
@@ -168,14 +407,30 @@ func resourceRabataS3BucketObjectPut(ctx context.Context, d *schema.ResourceData
 			return diag.Errorf("Error opening S3 bucket object source (%s): %s", path, err)
 		}
 
-		body = file
-
 		defer func() {
 			err := file.Close()
 			if err != nil {
 				log.Printf("[WARN] Error closing S3 bucket object source (%s): %s", path, err)
 			}
 		}()
+
+		if _, ok := d.GetOk("content_type"); !ok {
+			sourceContentType, err = detectS3ObjectContentType(file, path)
+			if err != nil {
+				return diag.Errorf("Error detecting S3 bucket object source (%s) content type: %s", path, err)
+			}
+		}
+
+		body = file
+
+		if awsClient.logUploadProgress {
+			var size int64
+			if info, err := file.Stat(); err == nil {
+				size = info.Size()
+			}
+
+			body = newProgressReader(file, path, size)
+		}
 	} else if v, ok := d.GetOk("content"); ok {
 		content := v.(string) //nolint:forcetypeassert
 		body = bytes.NewReader([]byte(content))
@@ -194,14 +449,35 @@ func resourceRabataS3BucketObjectPut(ctx context.Context, d *schema.ResourceData
 	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
 	key := d.Get("key").(string)       //nolint:forcetypeassert
 
-	//nolint:forcetypeassert
+	// The compressed body replaces the original one, so that the ETag checks and
+	// the multipart threshold apply to the bytes actually uploaded.
+	gzipBody := d.Get("gzip").(bool) //nolint:forcetypeassert
+	if gzipBody && body != nil {
+		compressed, err := gzipS3ObjectBody(body)
+		if err != nil {
+			return diag.Errorf("error compressing S3 bucket object body: %s", err)
+		}
+
+		body = compressed
+	}
+
 	putInput := &s3.PutObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-		ACL:    aws.String(d.Get("acl").(string)),
 		Body:   body,
 	}
 
+	// Grant headers and a canned ACL are mutually exclusive in a single request,
+	// so the full ACL is sent with the object and no PutObjectAcl is needed.
+	if hasS3ObjectGrantHeaders(d) {
+		putInput.GrantFullControl = s3ObjectGrantHeader(d, "grant_full_control")
+		putInput.GrantRead = s3ObjectGrantHeader(d, "grant_read")
+		putInput.GrantReadACP = s3ObjectGrantHeader(d, "grant_read_acp")
+		putInput.GrantWriteACP = s3ObjectGrantHeader(d, "grant_write_acp")
+	} else {
+		putInput.ACL = aws.String(s3ObjectCannedACL(d))
+	}
+
 	if v, ok := d.GetOk("storage_class"); ok {
 		putInput.StorageClass = aws.String(v.(string)) //nolint:forcetypeassert
 	}
@@ -210,16 +486,44 @@ func resourceRabataS3BucketObjectPut(ctx context.Context, d *schema.ResourceData
 		putInput.CacheControl = aws.String(v.(string)) //nolint:forcetypeassert
 	}
 
-	if v, ok := d.GetOk("content_type"); ok {
-		putInput.ContentType = aws.String(v.(string)) //nolint:forcetypeassert
+	contentType := d.Get("content_type").(string) //nolint:forcetypeassert
+	_, hasContent := d.GetOk("content")
+
+	if v := s3ObjectContentType(contentType, hasContent, sourceContentType); v != "" {
+		putInput.ContentType = aws.String(v)
+	}
+
+	metadata := d.Get("metadata").(map[string]any) //nolint:forcetypeassert
+
+	if v, ok := d.GetOk("metadata_json"); ok {
+		m, err := expandS3MetadataJSON(v.(string)) //nolint:forcetypeassert
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		// Conflicting keys are rejected at plan time.
+		maps.Copy(m, metadata)
+		metadata = m
+	}
+
+	if d.Get("source_metadata_sidecar").(bool) { //nolint:forcetypeassert
+		m, err := readS3ObjectMetadataSidecar(d.Get("source").(string)) //nolint:forcetypeassert
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		maps.Copy(m, metadata)
+		metadata = m
 	}
 
-	if v, ok := d.GetOk("metadata"); ok {
-		putInput.Metadata = stringMapToPointers(v.(map[string]any)) //nolint:forcetypeassert
+	if len(metadata) > 0 {
+		putInput.Metadata = stringMapToPointers(metadata)
 	}
 
 	if v, ok := d.GetOk("content_encoding"); ok {
 		putInput.ContentEncoding = aws.String(v.(string)) //nolint:forcetypeassert
+	} else if gzipBody {
+		putInput.ContentEncoding = aws.String(s3ObjectContentEncodingGzip)
 	}
 
 	if v, ok := d.GetOk("content_language"); ok {
@@ -230,172 +534,1285 @@ func resourceRabataS3BucketObjectPut(ctx context.Context, d *schema.ResourceData
 		putInput.ContentDisposition = aws.String(v.(string)) //nolint:forcetypeassert
 	}
 
-	if _, err := s3conn.PutObjectWithContext(ctx, putInput); err != nil {
-		return diag.Errorf("Error putting object in S3 bucket (%s): %s", bucket, err)
+	if v, ok := d.GetOk("attachment_filename"); ok {
+		putInput.ContentDisposition = aws.String(s3AttachmentContentDisposition(v.(string))) //nolint:forcetypeassert
 	}
 
-	d.SetId(key)
+	// Some gateways require the Content-Length header instead of working it out from the body.
+	// Multipart uploads ignore it, the length of each part is sent instead.
+	if v, ok := d.GetOk("content_length"); ok {
+		contentLength := int64(v.(int)) //nolint:forcetypeassert
 
-	return resourceRabataS3BucketObjectRead(ctx, d, meta)
-}
+		if _, inMemory := body.(*bytes.Reader); inMemory {
+			if size := s3ObjectBodySize(body); size != contentLength {
+				return diag.Errorf("content_length (%d) does not match the size of the content (%d bytes)",
+					contentLength, size)
+			}
+		}
 
-func resourceRabataS3BucketObjectCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	return resourceRabataS3BucketObjectPut(ctx, d, meta)
-}
+		putInput.ContentLength = aws.Int64(contentLength)
+	}
 
-func resourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+	if v, ok := d.GetOk("object_lock_legal_hold_status"); ok {
+		putInput.ObjectLockLegalHoldStatus = aws.String(v.(string)) //nolint:forcetypeassert
+	}
 
-	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
-	key := d.Get("key").(string)       //nolint:forcetypeassert
+	// An encryption context is only used by SSE-KMS.
+	if v, ok := d.GetOk("sse_kms_encryption_context"); ok {
+		putInput.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		putInput.SSEKMSEncryptionContext = aws.String(v.(string)) //nolint:forcetypeassert
+	}
 
-	resp, err := s3conn.HeadObjectWithContext(
-		ctx,
-		&s3.HeadObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-		},
-	)
-	if err != nil {
-		var awsErr awserr.RequestFailure
-		// If S3 returns a 404 Request Failure, mark the object as destroyed
-		if errors.As(err, &awsErr) && awsErr.StatusCode() == http.StatusNotFound {
-			d.SetId("")
-			log.Printf("[WARN] Error Reading Object (%s), object not found (HTTP status 404)", key)
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		putInput.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
 
-			return nil
-		}
+	putInput.RequestPayer = s3RequestPayer(d)
 
-		return diag.FromErr(err)
+	etagVerification := d.Get("etag_verification").(string) //nolint:forcetypeassert
+
+	var bodyMD5 string
+
+	if etagVerification != "" {
+		var err error
+		if bodyMD5, err = s3ObjectBodyMD5(body); err != nil {
+			return diag.Errorf("error computing MD5 of S3 bucket object body: %s", err)
+		}
 	}
 
-	log.Printf("[DEBUG] Reading S3 Bucket Object meta: %s", resp)
+	checksumSHA256 := d.Get("checksum_sha256").(bool) //nolint:forcetypeassert
 
-	d.Set("cache_control", resp.CacheControl)             //nolint:errcheck
-	d.Set("content_disposition", resp.ContentDisposition) //nolint:errcheck
-	d.Set("content_encoding", resp.ContentEncoding)       //nolint:errcheck
-	d.Set("content_language", resp.ContentLanguage)       //nolint:errcheck
-	d.Set("content_type", resp.ContentType)               //nolint:errcheck
-	metadata := pointersMapToStringList(resp.Metadata)
+	var bodySHA256 string
 
-	// AWS Go SDK capitalizes metadata, this is a workaround. https://github.com/aws/aws-sdk-go/issues/445
-	for k, v := range metadata {
-		delete(metadata, k)
-		metadata[strings.ToLower(k)] = v
-	}
+	if checksumSHA256 {
+		sum, err := hashS3ObjectBody(body, sha256.New())
+		if err != nil {
+			return diag.Errorf("error computing SHA-256 of S3 bucket object body: %s", err)
+		}
 
-	if err := d.Set("metadata", metadata); err != nil {
-		return diag.Errorf("error setting metadata: %s", err)
+		bodySHA256 = base64.StdEncoding.EncodeToString(sum)
 	}
 
-	d.Set("version_id", resp.VersionId) //nolint:errcheck
+	var (
+		putOutput *s3.PutObjectOutput
+		err       error
+	)
 
-	// See https://forums.aws.amazon.com/thread.jspa?threadID=44003
-	d.Set("etag", strings.Trim(aws.StringValue(resp.ETag), `"`)) //nolint:errcheck
+	if size := s3ObjectBodySize(body); awsClient.multipartThreshold > 0 && size >= awsClient.multipartThreshold {
+		log.Printf("[DEBUG] Uploading S3 Bucket (%s) Object (%s) of %d bytes in parts", bucket, key, size)
 
-	// The "STANDARD" (which is also the default) storage
-	// class when set would not be included in the results.
-	storageClass := s3.StorageClassStandard
-	if resp.StorageClass != nil {
-		storageClass = *resp.StorageClass
-	}
+		err = uploadS3ObjectMultipart(ctx, s3conn, putInput, awsClient.multipartPartSize)
+	} else {
+		// The SDK does not compute flexible checksums, so the header is sent as is.
+		// It is independent of DisableComputeChecksums, which only covers Content-MD5.
+		if checksumSHA256 {
+			putInput.ChecksumAlgorithm = aws.String(s3.ChecksumAlgorithmSha256)
+			putInput.ChecksumSHA256 = aws.String(bodySHA256)
+		}
 
-	d.Set("storage_class", storageClass) //nolint:errcheck
+		putOutput, err = s3conn.PutObjectWithContext(ctx, putInput)
+	}
 
-	return nil
-}
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
 
-func resourceRabataS3BucketObjectUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	// Changes to any of these attributes requires creation of a new object version (if bucket is versioned):
-	attributes := []string{
-		"cache_control",
-		"content_base64",
-		"content_disposition",
-		"content_encoding",
-		"content_language",
-		"content_type",
-		"content",
-		"etag",
-		"metadata",
-		"source",
-		"storage_class",
+		return diag.Errorf("Error putting object in S3 bucket (%s): %s", bucket, err)
 	}
 
-	if slices.ContainsFunc(attributes, d.HasChange) {
-		return resourceRabataS3BucketObjectPut(ctx, d, meta)
+	if checksumSHA256 && putOutput != nil {
+		if err := verifyS3ObjectChecksumSHA256(bodySHA256, putOutput); err != nil {
+			// Don't leave an object with an unverified body behind.
+			if delErr := deleteS3ObjectVersion(ctx, s3conn, bucket, key, aws.StringValue(putOutput.VersionId),
+				expectedBucketOwner, aws.StringValue(putInput.RequestPayer), false); delErr != nil {
+				return diag.Errorf("error verifying S3 Bucket (%s) Object (%s): %s; error deleting object: %s",
+					bucket, key, err, delErr)
+			}
+
+			return diag.Errorf("error verifying S3 Bucket (%s) Object (%s), object deleted: %s", bucket, key, err)
+		}
 	}
 
-	conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+	d.SetId(key)
+
+	var diags diag.Diagnostics
 
-	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
-	key := d.Get("key").(string)       //nolint:forcetypeassert
+	// The ETag of multipart uploads is not an MD5, so only single part uploads are verified.
+	if etagVerification != "" && putOutput != nil {
+		if err := verifyS3ObjectETag(bodyMD5, putOutput); err != nil {
+			if etagVerification == s3ObjectETagVerificationError {
+				return diag.Errorf("error verifying S3 Bucket (%s) Object (%s): %s", bucket, key, err)
+			}
 
-	if d.HasChange("acl") {
-		//nolint:forcetypeassert
-		_, err := conn.PutObjectAclWithContext(
-			ctx,
-			&s3.PutObjectAclInput{
-				Bucket: aws.String(bucket),
-				Key:    aws.String(key),
-				ACL:    aws.String(d.Get("acl").(string)),
-			},
-		)
-		if err != nil {
-			return diag.Errorf("error putting S3 object ACL: %s", err)
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("S3 Bucket (%s) Object (%s) ETag mismatch", bucket, key),
+				Detail:   err.Error(),
+			})
 		}
 	}
 
-	return resourceRabataS3BucketObjectRead(ctx, d, meta)
+	if _, ok := d.GetOk("expire_after"); ok {
+		if err := putS3ObjectExpireAfterTag(ctx, s3conn, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return append(diags, resourceRabataS3BucketObjectRead(ctx, d, meta)...)
 }
 
-func resourceRabataS3BucketObjectDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+// gzipS3ObjectBody reads body and returns it compressed with gzip.
+func gzipS3ObjectBody(body io.Reader) (*bytes.Reader, error) {
+	var buf bytes.Buffer
 
-	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
-	key := d.Get("key").(string)       //nolint:forcetypeassert
-	// We are effectively ignoring any leading '/' in the key name as aws.Config.DisableRestProtocolURICleaning is false
-	key = strings.TrimPrefix(key, "/")
+	w := gzip.NewWriter(&buf)
 
-	var err error
-	if _, ok := d.GetOk("version_id"); ok {
-		//nolint:forcetypeassert
-		err = deleteAllS3Objects(
-			ctx,
-			s3conn,
-			bucket,
-			key,
-			d.Get("force_destroy").(bool),
-			false,
-		)
-	} else {
-		err = deleteS3ObjectVersion(ctx, s3conn, bucket, key, "", false)
+	if _, err := io.Copy(w, body); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
 	}
 
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+// s3ObjectBodyMD5 returns the hex MD5 of body and rewinds it for the upload.
+func s3ObjectBodyMD5(body io.ReadSeeker) (string, error) {
+	sum, err := hashS3ObjectBody(body, md5.New()) //nolint:gosec
 	if err != nil {
-		return diag.Errorf("error deleting S3 Bucket (%s) Object (%s): %s", bucket, key, err)
+		return "", err
 	}
 
-	return nil
+	return hex.EncodeToString(sum), nil
 }
 
-func validateMetadataIsLowerCase(v any, _ string) ([]string, []error) {
-	value := v.(map[string]any) //nolint:forcetypeassert
+// hashS3ObjectBody returns the hash of body and rewinds it for the upload.
+func hashS3ObjectBody(body io.ReadSeeker, hash hash.Hash) ([]byte, error) {
+	if body == nil {
+		return hash.Sum(nil), nil
+	}
 
-	var errs []error
+	// Do not log the hashing as upload progress.
+	if r, ok := body.(*progressReader); ok {
+		body = r.ReadSeeker
+	}
 
-	for k := range value {
-		if k != strings.ToLower(k) {
-			errs = append(errs, fmt.Errorf(
-				"metadata must be lowercase only. Offending key: %q", k))
-		}
+	start, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, errs
-}
+	if _, err := io.Copy(hash, body); err != nil {
+		return nil, err
+	}
 
-func resourceRabataS3BucketObjectCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
-	if d.HasChange("etag") {
-		d.SetNewComputed("version_id") //nolint:errcheck
+	if _, err := body.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// verifyS3ObjectETag compares the MD5 of the uploaded body with the returned ETag.
+// Objects encrypted with SSE-KMS or SSE-C have an ETag that is not the MD5 of the body and are not verified.
+func verifyS3ObjectETag(bodyMD5 string, out *s3.PutObjectOutput) error {
+	etag := strings.Trim(aws.StringValue(out.ETag), `"`)
+
+	if out.SSECustomerAlgorithm != nil ||
+		aws.StringValue(out.ServerSideEncryption) == s3.ServerSideEncryptionAwsKms ||
+		!s3MD5ETagRegexp.MatchString(etag) {
+		log.Printf("[DEBUG] Skipping verification of S3 object ETag %q, it is not an MD5", etag)
+
+		return nil
+	}
+
+	if !strings.EqualFold(etag, bodyMD5) {
+		return fmt.Errorf("ETag %s does not match the MD5 of the uploaded body %s", etag, bodyMD5)
+	}
+
+	return nil
+}
+
+// verifyS3ObjectChecksumSHA256 compares the base64 SHA-256 of the uploaded body with the returned checksum.
+// Backends that do not return checksums are not verified.
+func verifyS3ObjectChecksumSHA256(bodySHA256 string, out *s3.PutObjectOutput) error {
+	checksum := aws.StringValue(out.ChecksumSHA256)
+	if checksum == "" {
+		log.Printf("[DEBUG] Skipping verification of S3 object SHA-256 checksum, none returned")
+
+		return nil
+	}
+
+	if checksum != bodySHA256 {
+		return fmt.Errorf("SHA-256 checksum %s does not match the uploaded body %s", checksum, bodySHA256)
+	}
+
+	return nil
+}
+
+// s3ObjectBodySize returns the number of bytes left to read from body, or 0 if it cannot be determined.
+func s3ObjectBodySize(body io.ReadSeeker) int64 {
+	if body == nil {
+		return 0
+	}
+
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0
+	}
+
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return 0
+	}
+
+	return size
+}
+
+// uploadS3ObjectMultipart uploads the object described by input in parts of partSize bytes.
+func uploadS3ObjectMultipart(ctx context.Context, conn s3iface.S3API, input *s3.PutObjectInput, partSize int64) error {
+	uploader := s3manager.NewUploaderWithClient(conn, func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+	})
+
+	_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		ACL:                       input.ACL,
+		Body:                      input.Body,
+		Bucket:                    input.Bucket,
+		CacheControl:              input.CacheControl,
+		ContentDisposition:        input.ContentDisposition,
+		ContentEncoding:           input.ContentEncoding,
+		ContentLanguage:           input.ContentLanguage,
+		ContentType:               input.ContentType,
+		ExpectedBucketOwner:       input.ExpectedBucketOwner,
+		GrantFullControl:          input.GrantFullControl,
+		GrantRead:                 input.GrantRead,
+		GrantReadACP:              input.GrantReadACP,
+		GrantWriteACP:             input.GrantWriteACP,
+		Key:                       input.Key,
+		Metadata:                  input.Metadata,
+		ObjectLockLegalHoldStatus: input.ObjectLockLegalHoldStatus,
+		RequestPayer:              input.RequestPayer,
+		SSEKMSEncryptionContext:   input.SSEKMSEncryptionContext,
+		ServerSideEncryption:      input.ServerSideEncryption,
+		StorageClass:              input.StorageClass,
+	})
+
+	return err
+}
+
+func resourceRabataS3BucketObjectCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	return resourceRabataS3BucketObjectPut(ctx, d, meta)
+}
+
+func resourceRabataS3BucketObjectRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string)                             //nolint:forcetypeassert
+	key := d.Get("key").(string)                                   //nolint:forcetypeassert
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: s3RequestPayer(d),
+	}
+
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	resp, err := s3conn.HeadObjectWithContext(ctx, input)
+	if err != nil {
+		var awsErr awserr.RequestFailure
+		// If S3 returns a 404 Request Failure, mark the object as destroyed
+		if errors.As(err, &awsErr) && awsErr.StatusCode() == http.StatusNotFound {
+			d.SetId("")
+			log.Printf("[WARN] Error Reading Object (%s), object not found (HTTP status 404)", key)
+
+			return nil
+		}
+
+		// A 403 says nothing about the existence of the object, so it is kept in the state.
+		if errors.As(err, &awsErr) && awsErr.StatusCode() == http.StatusForbidden {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Access denied reading S3 Bucket (%s) Object (%s)", bucket, key),
+				Detail: "The credentials of the provider are not allowed to read the object, check the bucket " +
+					"policy and the object ACL. The object is kept in the state.\n\n" +
+					annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner).Error(),
+			}}
+		}
+
+		return diag.FromErr(annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner))
+	}
+
+	log.Printf("[DEBUG] Reading S3 Bucket Object meta: %s", resp)
+
+	set := s3ObjectComputedAttributeSetter(d)
+
+	set("cache_control", resp.CacheControl)                        //nolint:errcheck
+	set("content_disposition", resp.ContentDisposition)            //nolint:errcheck
+	set("content_language", aws.StringValue(resp.ContentLanguage)) //nolint:errcheck
+	set("content_type", resp.ContentType)                          //nolint:errcheck
+
+	// The encoding set by gzip is not part of the content_encoding attribute.
+	gzipBody := d.Get("gzip").(bool) //nolint:forcetypeassert
+	if gzipBody && aws.StringValue(resp.ContentEncoding) == s3ObjectContentEncodingGzip {
+		set("content_encoding", "") //nolint:errcheck
+	} else {
+		set("content_encoding", resp.ContentEncoding) //nolint:errcheck
+	}
+
+	metadata := pointersMapToStringList(resp.Metadata)
+
+	// AWS Go SDK capitalizes metadata, this is a workaround. https://github.com/aws/aws-sdk-go/issues/445
+	for k, v := range metadata {
+		delete(metadata, k)
+		metadata[strings.ToLower(k)] = v
+	}
+
+	// Some backends return their own bookkeeping as user metadata, which would never match the configuration.
+	// Keys set in the configuration are kept, whatever their prefix.
+	configuredMetadata := d.Get("metadata").(map[string]any) //nolint:forcetypeassert
+	maps.DeleteFunc(metadata, func(k string, _ any) bool {
+		_, ok := configuredMetadata[k]
+
+		return !ok && isS3SystemMetadataKey(k)
+	})
+
+	// Keys set with metadata_json are not part of the metadata attribute.
+	if v, ok := d.GetOk("metadata_json"); ok {
+		if m, err := expandS3MetadataJSON(v.(string)); err == nil { //nolint:forcetypeassert
+			for k := range m {
+				delete(metadata, k)
+			}
+		}
+	}
+
+	// Nor are the keys read from the sidecar file of the source.
+	if d.Get("source_metadata_sidecar").(bool) { //nolint:forcetypeassert
+		if m, err := readS3ObjectMetadataSidecar(d.Get("source").(string)); err == nil { //nolint:forcetypeassert
+			configured := d.Get("metadata").(map[string]any) //nolint:forcetypeassert
+			for k := range m {
+				if _, ok := configured[k]; !ok {
+					delete(metadata, k)
+				}
+			}
+		}
+	}
+
+	if err := set("metadata", metadata); err != nil {
+		return diag.Errorf("error setting metadata: %s", err)
+	}
+
+	d.Set("version_id", resp.VersionId)                                  //nolint:errcheck
+	set("object_lock_legal_hold_status", resp.ObjectLockLegalHoldStatus) //nolint:errcheck
+
+	// See https://forums.aws.amazon.com/thread.jspa?threadID=44003
+	d.Set("etag", strings.Trim(aws.StringValue(resp.ETag), `"`)) //nolint:errcheck
+
+	// The "STANDARD" (which is also the default) storage
+	// class when set would not be included in the results.
+	storageClass := s3.StorageClassStandard
+	if resp.StorageClass != nil {
+		storageClass = *resp.StorageClass
+	}
+
+	set("storage_class", storageClass) //nolint:errcheck
+
+	if d.Get("track_acl").(bool) { //nolint:forcetypeassert
+		if err := resourceRabataS3BucketObjectACLRead(ctx, s3conn, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if v, ok := d.GetOk("expire_after"); ok && resp.LastModified != nil {
+		expireAfter, _ := time.ParseDuration(v.(string)) //nolint:forcetypeassert // Validated at plan time.
+		expiresAt := resp.LastModified.Add(expireAfter).UTC()
+
+		d.Set("expires_at", expiresAt.Format(time.RFC3339)) //nolint:errcheck
+	} else {
+		d.Set("expires_at", "") //nolint:errcheck
+	}
+
+	return nil
+}
+
+// s3ObjectComputedAttributeSetter returns a d.Set that ignores the attributes not selected by computed_attributes.
+func s3ObjectComputedAttributeSetter(d *schema.ResourceData) func(string, any) error {
+	selected := d.Get("computed_attributes").(*schema.Set) //nolint:forcetypeassert
+
+	return func(k string, v any) error {
+		if selected.Len() > 0 && !selected.Contains(k) {
+			return nil
+		}
+
+		return d.Set(k, v)
+	}
+}
+
+// resourceRabataS3BucketObjectACLRead reads the object ACL and sets acl to the
+// canned ACL it corresponds to, so that external ACL changes show up in the plan.
+func resourceRabataS3BucketObjectACLRead(ctx context.Context, conn s3iface.S3API, d *schema.ResourceData) error {
+	acl := s3ObjectCannedACL(d)
+
+	// Grants of these canned ACLs depend on the bucket owner or are
+	// not distinguishable from the others, so drift can't be detected.
+	switch acl {
+	case s3.ObjectCannedACLAwsExecRead, s3.ObjectCannedACLBucketOwnerRead, s3.ObjectCannedACLBucketOwnerFullControl:
+		return nil
+	}
+
+	if hasS3ObjectGrantHeaders(d) {
+		return nil
+	}
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	input := &s3.GetObjectAclInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: s3RequestPayer(d),
+	}
+
+	if v, ok := d.GetOk("expected_bucket_owner"); ok {
+		input.ExpectedBucketOwner = aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	out, err := conn.GetObjectAclWithContext(ctx, input)
+	if err != nil {
+		return fmt.Errorf("error reading S3 Bucket (%s) Object (%s) ACL: %w", bucket, key, err)
+	}
+
+	// An ACL that doesn't match any canned ACL is stored as an empty string,
+	// which differs from every configured value.
+	return d.Set("acl", s3ObjectCannedACLFromGrants(out.Owner, out.Grants))
+}
+
+// s3ObjectCannedACL returns the canned ACL to put the object with.
+func s3ObjectCannedACL(d *schema.ResourceData) string {
+	if d.Get("grant_bucket_owner_full_control").(bool) { //nolint:forcetypeassert
+		return s3.ObjectCannedACLBucketOwnerFullControl
+	}
+
+	return d.Get("acl").(string) //nolint:forcetypeassert
+}
+
+// s3ObjectCannedACLFromGrants returns the canned ACL matching the grants, or an empty string.
+func s3ObjectCannedACLFromGrants(owner *s3.Owner, grants []*s3.Grant) string {
+	const (
+		allUsersURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+		authenticatedUsersURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	)
+
+	var (
+		ownerFullControl bool
+		other            []string
+	)
+
+	for _, grant := range grants {
+		if grant.Grantee == nil {
+			continue
+		}
+
+		permission := aws.StringValue(grant.Permission)
+
+		if owner != nil && aws.StringValue(grant.Grantee.ID) == aws.StringValue(owner.ID) &&
+			permission == s3.PermissionFullControl {
+			ownerFullControl = true
+
+			continue
+		}
+
+		other = append(other, aws.StringValue(grant.Grantee.URI)+":"+permission)
+	}
+
+	if !ownerFullControl {
+		return ""
+	}
+
+	slices.Sort(other)
+
+	switch strings.Join(other, ",") {
+	case "":
+		return s3.ObjectCannedACLPrivate
+	case allUsersURI + ":" + s3.PermissionRead:
+		return s3.ObjectCannedACLPublicRead
+	case allUsersURI + ":" + s3.PermissionRead + "," + allUsersURI + ":" + s3.PermissionWrite:
+		return s3.ObjectCannedACLPublicReadWrite
+	case authenticatedUsersURI + ":" + s3.PermissionRead:
+		return s3.ObjectCannedACLAuthenticatedRead
+	default:
+		return ""
+	}
+}
+
+func resourceRabataS3BucketObjectUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	// Changes to any of these attributes requires creation of a new object version (if bucket is versioned):
+	attributes := []string{
+		"attachment_filename",
+		"cache_control",
+		"content_base64",
+		"content_disposition",
+		"content_encoding",
+		"content_language",
+		"content_length",
+		"content_type",
+		"content",
+		"etag",
+		"gzip",
+		"metadata",
+		"metadata_json",
+		"source",
+		"source_metadata_sidecar",
+		"sse_kms_encryption_context",
+	}
+
+	if slices.ContainsFunc(attributes, d.HasChange) {
+		return resourceRabataS3BucketObjectPut(ctx, d, meta)
+	}
+
+	awsClient := meta.(*AWSClient) //nolint:forcetypeassert
+	conn := awsClient.s3conn
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	// The body is unchanged, so the storage class is changed with a server-side copy
+	// of the object onto itself instead of uploading the body again.
+	if d.HasChange("storage_class") {
+		if err := resourceRabataS3BucketObjectStorageClassUpdate(ctx, conn, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// A legal hold is set on the current version of the object and does not create a new one.
+	if d.HasChange("object_lock_legal_hold_status") {
+		if err := resourceRabataS3BucketObjectLegalHoldUpdate(ctx, conn, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("expire_after") {
+		if err := putS3ObjectExpireAfterTag(ctx, conn, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// PutObjectAcl only replaces the ACL subresource: the metadata, headers and version of the object
+	// are left as they are, so ACL-only changes never lose metadata. The storage class copy above
+	// already applied the ACL to the new copy.
+	if d.HasChanges("acl", "grant_bucket_owner_full_control",
+		"grant_full_control", "grant_read", "grant_read_acp", "grant_write_acp") &&
+		!d.HasChange("storage_class") {
+		input := &s3.PutObjectAclInput{
+			Bucket:       aws.String(bucket),
+			Key:          aws.String(key),
+			RequestPayer: s3RequestPayer(d),
+		}
+
+		if hasS3ObjectGrantHeaders(d) {
+			input.GrantFullControl = s3ObjectGrantHeader(d, "grant_full_control")
+			input.GrantRead = s3ObjectGrantHeader(d, "grant_read")
+			input.GrantReadACP = s3ObjectGrantHeader(d, "grant_read_acp")
+			input.GrantWriteACP = s3ObjectGrantHeader(d, "grant_write_acp")
+		} else {
+			input.ACL = aws.String(s3ObjectCannedACL(d))
+		}
+
+		expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+		if expectedBucketOwner != "" {
+			input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+		}
+
+		if _, err := conn.PutObjectAclWithContext(ctx, input); err != nil {
+			err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+			return diag.Errorf("error putting S3 object ACL: %s", err)
+		}
+	}
+
+	return resourceRabataS3BucketObjectRead(ctx, d, meta)
+}
+
+// getS3ObjectTags returns the object tags.
+func getS3ObjectTags(ctx context.Context, conn s3iface.S3API, d *schema.ResourceData) (map[string]string, error) {
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	input := &s3.GetObjectTaggingInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: s3RequestPayer(d),
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	out, err := conn.GetObjectTaggingWithContext(ctx, input)
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return nil, fmt.Errorf("error reading S3 Bucket (%s) Object (%s) tags: %w", bucket, key, err)
+	}
+
+	tags := make(map[string]string, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return tags, nil
+}
+
+// putS3ObjectExpireAfterTag sets the expire-after tag to the days of expire_after, or removes it
+// when expire_after is not set. A fixed value per duration lets lifecycle rules filter on the tag.
+// Other tags of the object are kept.
+func putS3ObjectExpireAfterTag(ctx context.Context, conn s3iface.S3API, d *schema.ResourceData) error {
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	tags, err := getS3ObjectTags(ctx, conn, d)
+	if err != nil {
+		return err
+	}
+
+	delete(tags, s3ObjectExpireAfterTagKey)
+
+	if v, ok := d.GetOk("expire_after"); ok {
+		expireAfter, _ := time.ParseDuration(v.(string)) //nolint:forcetypeassert // Validated at plan time.
+		tags[s3ObjectExpireAfterTagKey] = s3ObjectExpireAfterTagValue(expireAfter)
+	}
+
+	input := &s3.PutObjectTaggingInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: s3RequestPayer(d),
+		Tagging:      &s3.Tagging{TagSet: make([]*s3.Tag, 0, len(tags))},
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		input.Tagging.TagSet = append(input.Tagging.TagSet, &s3.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(tags[k]),
+		})
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	log.Printf("[DEBUG] Putting S3 Bucket (%s) Object (%s) tags: %s", bucket, key, input.Tagging)
+
+	if _, err := conn.PutObjectTaggingWithContext(ctx, input); err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return fmt.Errorf("error putting S3 Bucket (%s) Object (%s) tags: %w", bucket, key, err)
+	}
+
+	return nil
+}
+
+func resourceRabataS3BucketObjectLegalHoldUpdate(
+	ctx context.Context,
+	conn s3iface.S3API,
+	d *schema.ResourceData,
+) error {
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	// Removing the attribute from the configuration releases the hold.
+	status := d.Get("object_lock_legal_hold_status").(string) //nolint:forcetypeassert
+	if status == "" {
+		status = s3.ObjectLockLegalHoldStatusOff
+	}
+
+	input := &s3.PutObjectLegalHoldInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: s3RequestPayer(d),
+		LegalHold: &s3.ObjectLockLegalHold{
+			Status: aws.String(status),
+		},
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	log.Printf("[DEBUG] Putting S3 Bucket (%s) Object (%s) legal hold: %s", bucket, key, status)
+
+	if _, err := conn.PutObjectLegalHoldWithContext(ctx, input); err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return fmt.Errorf("error putting S3 Bucket (%s) Object (%s) legal hold: %w", bucket, key, err)
+	}
+
+	return nil
+}
+
+func resourceRabataS3BucketObjectStorageClassUpdate(
+	ctx context.Context,
+	conn s3iface.S3API,
+	d *schema.ResourceData,
+) error {
+	bucket := d.Get("bucket").(string)              //nolint:forcetypeassert
+	key := d.Get("key").(string)                    //nolint:forcetypeassert
+	storageClass := d.Get("storage_class").(string) //nolint:forcetypeassert
+
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(bucket + "/" + url.PathEscape(key)),
+		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+		RequestPayer:      s3RequestPayer(d),
+		StorageClass:      aws.String(storageClass),
+	}
+
+	// The copy is a new version, which would not be held otherwise.
+	if v, ok := d.GetOk("object_lock_legal_hold_status"); ok {
+		input.ObjectLockLegalHoldStatus = aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	// CopyObject does not keep the ACL of the source object.
+	if hasS3ObjectGrantHeaders(d) {
+		input.GrantFullControl = s3ObjectGrantHeader(d, "grant_full_control")
+		input.GrantRead = s3ObjectGrantHeader(d, "grant_read")
+		input.GrantReadACP = s3ObjectGrantHeader(d, "grant_read_acp")
+		input.GrantWriteACP = s3ObjectGrantHeader(d, "grant_write_acp")
+	} else {
+		input.ACL = aws.String(s3ObjectCannedACL(d))
+	}
+
+	expectedBucketOwner := d.Get("expected_bucket_owner").(string) //nolint:forcetypeassert
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+		input.ExpectedSourceBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	// The copy is encrypted with the bucket default unless the encryption of the object is sent again.
+	head, err := conn.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(bucket),
+		Key:                 aws.String(key),
+		ExpectedBucketOwner: input.ExpectedBucketOwner,
+		RequestPayer:        input.RequestPayer,
+	})
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return fmt.Errorf("error reading S3 Bucket (%s) Object (%s) encryption: %w", bucket, key, err)
+	}
+
+	if head.SSECustomerAlgorithm != nil {
+		return fmt.Errorf("S3 Bucket (%s) Object (%s) is encrypted with a customer provided key (SSE-C), "+
+			"its storage class cannot be changed without the key", bucket, key)
+	}
+
+	input.ServerSideEncryption = head.ServerSideEncryption
+	input.SSEKMSKeyId = head.SSEKMSKeyId
+	input.BucketKeyEnabled = head.BucketKeyEnabled
+
+	// An encryption context is only used by SSE-KMS.
+	if v, ok := d.GetOk("sse_kms_encryption_context"); ok {
+		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		input.SSEKMSEncryptionContext = aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	log.Printf("[DEBUG] Changing S3 Bucket (%s) Object (%s) storage class to %s", bucket, key, storageClass)
+
+	if _, err := conn.CopyObjectWithContext(ctx, input); err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return fmt.Errorf("error changing S3 Bucket (%s) Object (%s) storage class: %w", bucket, key, err)
+	}
+
+	return nil
+}
+
+func resourceRabataS3BucketObjectDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	s3conn := awsClient.s3conn
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket, diags := requiredResourceDataString(d, "bucket")
+	if diags.HasError() {
+		return diags
+	}
+
+	key, diags := requiredResourceDataString(d, "key")
+	if diags.HasError() {
+		return diags
+	}
+
+	expectedBucketOwner, diags := resourceDataString(d, "expected_bucket_owner")
+	if diags.HasError() {
+		return diags
+	}
+
+	forceDestroy, diags := resourceDataBool(d, "force_destroy")
+	if diags.HasError() {
+		return diags
+	}
+
+	requestPayer, diags := resourceDataString(d, "request_payer")
+	if diags.HasError() {
+		return diags
+	}
+
+	// We are effectively ignoring any leading '/' in the key name as aws.Config.DisableRestProtocolURICleaning is false
+	key = strings.TrimPrefix(key, "/")
+	start := time.Now()
+
+	_, err := retryOnAWSCodes(ctx, d.Timeout(schema.TimeoutDelete), s3ThrottlingErrorCodes, func() (any, error) {
+		if _, ok := d.GetOk("version_id"); ok {
+			return nil, deleteAllS3Objects(
+				ctx,
+				s3conn,
+				bucket,
+				key,
+				expectedBucketOwner,
+				requestPayer,
+				forceDestroy,
+				false,
+			)
+		}
+
+		return nil, deleteS3ObjectVersion(ctx, s3conn, bucket, key, "", expectedBucketOwner, requestPayer, false)
+	})
+	if err != nil {
+		err = annotateExpectedBucketOwnerError(err, bucket, expectedBucketOwner)
+
+		return diag.Errorf("error deleting S3 Bucket (%s) Object (%s): %s", bucket, key, err)
+	}
+
+	waitForDeletion, diags := resourceDataBool(d, "wait_for_deletion")
+	if diags.HasError() {
+		return diags
+	}
+
+	if waitForDeletion {
+		remaining := d.Timeout(schema.TimeoutDelete) - time.Since(start)
+		timeout := max(remaining, s3ObjectDeletionMinWait)
+
+		if err := waitForS3ObjectDeletion(ctx, s3conn, bucket, key, expectedBucketOwner, requestPayer, timeout); err != nil {
+			if remaining < s3ObjectDeletionMinWait {
+				return diag.Errorf("error waiting for S3 Bucket (%s) Object (%s) deletion: "+
+					"the delete timeout was used up, waited a further %s: %s", bucket, key, timeout, err)
+			}
+
+			return diag.Errorf("error waiting for S3 Bucket (%s) Object (%s) deletion: %s", bucket, key, err)
+		}
+	}
+
+	return nil
+}
+
+// waitForS3ObjectDeletion polls the object with HeadObject until it is not found or timeout expires.
+// At least one HeadObject is always made.
+func waitForS3ObjectDeletion(
+	ctx context.Context,
+	conn s3iface.S3API,
+	bucket, key, expectedBucketOwner, requestPayer string,
+	timeout time.Duration,
+) error {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	if requestPayer != "" {
+		input.RequestPayer = aws.String(requestPayer)
+	}
+
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		_, err := conn.HeadObjectWithContext(ctx, input)
+		if isAWSErrRequestFailureStatusCode(err, http.StatusNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+
+		log.Printf("[DEBUG] S3 Bucket (%s) Object (%s) still exists", bucket, key)
+
+		return retry.RetryableError(fmt.Errorf("object %s still exists", key))
+	})
+}
+
+// s3SystemMetadataPrefixes are the metadata key prefixes reserved for the storage backend.
+var s3SystemMetadataPrefixes = []string{"x-amz-", "x-rabata-"}
+
+func isS3SystemMetadataKey(k string) bool {
+	return slices.ContainsFunc(s3SystemMetadataPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(strings.ToLower(k), prefix)
+	})
+}
+
+func validateMetadataIsLowerCase(v any, _ string) ([]string, []error) {
+	value := v.(map[string]any) //nolint:forcetypeassert
+
+	var errs []error
+
+	for k := range value {
+		if k != strings.ToLower(k) {
+			errs = append(errs, fmt.Errorf(
+				"metadata must be lowercase only. Offending key: %q", k))
+		}
+	}
+
+	return nil, errs
+}
+
+// suppressUnsetStringDiff suppresses the diff of an optional attribute between an unset and an empty value.
+// A remote value of an attribute removed from the configuration is still a diff, so that it is cleared.
+func suppressUnsetStringDiff(_, oldValue, newValue string, _ *schema.ResourceData) bool {
+	return oldValue == "" && newValue == ""
+}
+
+// validateS3ObjectExpireAfter validates an expire_after duration of at least one day in whole days.
+func validateS3ObjectExpireAfter(v any, k string) ([]string, []error) {
+	d, err := time.ParseDuration(v.(string)) //nolint:forcetypeassert
+	if err != nil {
+		return nil, []error{fmt.Errorf("%q must be a duration, e.g. 720h: %w", k, err)}
+	}
+
+	if d < s3ObjectExpireAfterUnit || d%s3ObjectExpireAfterUnit != 0 {
+		return nil, []error{fmt.Errorf("%q must be a whole number of days (multiple of %s), got %s",
+			k, s3ObjectExpireAfterUnit, d)}
+	}
+
+	return nil, nil
+}
+
+// s3ObjectExpireAfterTagValue returns the expire-after tag value of a duration, e.g. 30d for 720h.
+func s3ObjectExpireAfterTagValue(d time.Duration) string {
+	return fmt.Sprintf("%dd", d/s3ObjectExpireAfterUnit)
+}
+
+// suppressS3ObjectContentDispositionDiff suppresses the diff of a content_disposition
+// that is generated from attachment_filename.
+func suppressS3ObjectContentDispositionDiff(_, _, newValue string, d *schema.ResourceData) bool {
+	_, ok := d.GetOk("attachment_filename")
+
+	return ok && newValue == ""
+}
+
+// s3AttachmentContentDisposition returns an attachment Content-Disposition for filename.
+// Names that are not plain printable ASCII get an ASCII fallback in filename
+// and the exact name RFC 5987 encoded in filename*.
+func s3AttachmentContentDisposition(filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' || r == '"' || r == '\\' {
+			return '_'
+		}
+
+		return r
+	}, filename)
+
+	disposition := `attachment; filename="` + fallback + `"`
+	if fallback == filename {
+		return disposition
+	}
+
+	var encoded strings.Builder
+
+	for _, b := range []byte(filename) {
+		if isRFC5987AttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+
+	return disposition + "; filename*=UTF-8''" + encoded.String()
+}
+
+// isRFC5987AttrChar reports whether b may appear unencoded in an RFC 5987 ext-value.
+func isRFC5987AttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+// normalizeS3ETag strips the surrounding quotes S3 returns in ETag headers,
+// so that a quoted etag copied from an HTTP response compares equal to the read one.
+func normalizeS3ETag(v any) string {
+	etag, _ := v.(string)
+
+	return strings.Trim(etag, `"`)
+}
+
+// hasS3ObjectGrantHeaders returns true if any of the header-style grant attributes is set.
+func hasS3ObjectGrantHeaders(d *schema.ResourceData) bool {
+	for _, k := range []string{"grant_full_control", "grant_read", "grant_read_acp", "grant_write_acp"} {
+		if _, ok := d.GetOk(k); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// s3RequestPayer returns the configured request_payer, or nil to charge the bucket owner.
+func s3RequestPayer(d *schema.ResourceData) *string {
+	if v, ok := d.GetOk("request_payer"); ok {
+		return aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	return nil
+}
+
+// s3ObjectGrantHeader returns the value of a grant header attribute,
+// e.g. `id="canonical-user-id", uri="http://acs.amazonaws.com/groups/global/AllUsers"`.
+func s3ObjectGrantHeader(d *schema.ResourceData, k string) *string {
+	if v, ok := d.GetOk(k); ok {
+		return aws.String(v.(string)) //nolint:forcetypeassert
+	}
+
+	return nil
+}
+
+// s3GrantHeaderGranteeRegexp matches a single grantee of a grant header.
+var s3GrantHeaderGranteeRegexp = regexp.MustCompile(`^(id|uri|emailAddress)="[^"]+"$`)
+
+// validateS3GrantHeader validates a comma-separated list of grantees,
+// e.g. `id="canonical-user-id", uri="http://acs.amazonaws.com/groups/global/AllUsers"`.
+func validateS3GrantHeader(v any, k string) ([]string, []error) {
+	value, _ := v.(string)
+	if value == "" {
+		return nil, []error{fmt.Errorf("%q must not be empty", k)}
+	}
+
+	var errs []error
+
+	for grantee := range strings.SplitSeq(value, ",") {
+		grantee = strings.TrimSpace(grantee)
+		if !s3GrantHeaderGranteeRegexp.MatchString(grantee) {
+			errs = append(errs, fmt.Errorf(`%q: grantee %q must be id="...", uri="..." or emailAddress="..."`,
+				k, grantee))
+		}
+	}
+
+	return nil, errs
+}
+
+// expandS3MetadataJSON parses a JSON object of string values into object metadata.
+func expandS3MetadataJSON(s string) (map[string]any, error) {
+	var m map[string]string
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil, fmt.Errorf("metadata_json must be a JSON object of string values: %w", err)
+	}
+
+	metadata := make(map[string]any, len(m))
+	for k, v := range m {
+		metadata[k] = v
+	}
+
+	return metadata, nil
+}
+
+// s3ObjectContentSniffLen is the number of bytes http.DetectContentType considers.
+const s3ObjectContentSniffLen = 512
+
+// s3ObjectContentType returns the content type to upload the object with, or "" to leave it to the server.
+// The configured content type wins, inline content is text and a source file uses its detected type.
+func s3ObjectContentType(contentType string, hasContent bool, sourceContentType string) string {
+	switch {
+	case contentType != "":
+		return contentType
+	case hasContent:
+		return defaultS3ObjectContentType
+	default:
+		return sourceContentType
+	}
+}
+
+// detectS3ObjectContentType returns the content type of the source file from its extension,
+// or sniffed from its first bytes for extensionless or unknown files. The file is rewound.
+// http.DetectContentType does not recognize JSON, so text that is a JSON object or array is application/json.
+func detectS3ObjectContentType(file io.ReadSeeker, path string) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType, nil
+	}
+
+	head := make([]byte, s3ObjectContentSniffLen)
+
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+
+	if strings.HasPrefix(contentType, "text/plain") {
+		trimmed := bytes.TrimLeft(head, " \t\r\n")
+		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return "", err
+			}
+
+			if isJSONS3ObjectSource(file) {
+				contentType = "application/json"
+			}
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return contentType, nil
+}
+
+// isJSONS3ObjectSource reports whether r holds a single JSON value. The value is only tokenized,
+// so that large sources are not read into memory.
+func isJSONS3ObjectSource(r io.Reader) bool {
+	dec := json.NewDecoder(r)
+	depth := 0
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			break
+		}
+	}
+
+	_, err := dec.Token()
+
+	return errors.Is(err, io.EOF)
+}
+
+// s3ObjectMetadataSidecarSuffix is appended to the source path to find its metadata sidecar file.
+const s3ObjectMetadataSidecarSuffix = ".meta.json"
+
+// readS3ObjectMetadataSidecar returns the lowercased metadata of the sidecar file of source,
+// or an empty map if source has none.
+func readS3ObjectMetadataSidecar(source string) (map[string]any, error) {
+	path, err := homedir.Expand(source)
+	if err != nil {
+		return nil, fmt.Errorf("error expanding homedir in source (%s): %w", source, err)
+	}
+
+	path += s3ObjectMetadataSidecarSuffix
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("[DEBUG] No S3 bucket object metadata sidecar (%s)", path)
+
+		return map[string]any{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading S3 bucket object metadata sidecar (%s): %w", path, err)
+	}
+
+	m, err := expandS3MetadataJSON(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing S3 bucket object metadata sidecar (%s): %w", path, err)
+	}
+
+	metadata := make(map[string]any, len(m))
+	for k, v := range m {
+		metadata[strings.ToLower(k)] = v
+	}
+
+	if _, errs := validateMetadataIsLowerCase(metadata, path); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return metadata, nil
+}
+
+func validateS3MetadataJSON(v any, k string) ([]string, []error) {
+	metadata, err := expandS3MetadataJSON(v.(string)) //nolint:forcetypeassert
+	if err != nil {
+		return nil, []error{fmt.Errorf("%q: %w", k, err)}
+	}
+
+	return validateMetadataIsLowerCase(metadata, k)
+}
+
+func validateBase64EncodedJSON(v any, k string) ([]string, []error) {
+	value := v.(string) //nolint:forcetypeassert
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%q must be base64-encoded: %w", k, err)}
+	}
+
+	if !json.Valid(decoded) {
+		return nil, []error{fmt.Errorf("%q must be base64-encoded JSON", k)}
+	}
+
+	return nil, nil
+}
+
+// annotateExpectedBucketOwnerError wraps the HTTP 403 returned by S3 when
+// the bucket is not owned by the account in expected_bucket_owner.
+func annotateExpectedBucketOwnerError(err error, bucket, expectedBucketOwner string) error {
+	if expectedBucketOwner != "" && isAWSErrRequestFailureStatusCode(err, http.StatusForbidden) {
+		return fmt.Errorf("S3 bucket (%s) access denied, it may not be owned by expected bucket owner (%s): %w",
+			bucket, expectedBucketOwner, err)
+	}
+
+	return err
+}
+
+func resourceRabataS3BucketObjectCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	// A storage class change copies the object onto itself, which creates a new version as well.
+	if d.HasChanges("etag", "storage_class") {
+		d.SetNewComputed("version_id") //nolint:errcheck
+	}
+
+	if v, ok := d.GetOk("metadata_json"); ok {
+		m, err := expandS3MetadataJSON(v.(string)) //nolint:forcetypeassert
+		if err != nil {
+			return err
+		}
+
+		for k := range d.Get("metadata").(map[string]any) { //nolint:forcetypeassert
+			if _, ok := m[k]; ok {
+				return fmt.Errorf("metadata key %q is set in both metadata and metadata_json", k)
+			}
+		}
 	}
 
 	return nil
@@ -403,11 +1820,13 @@ func resourceRabataS3BucketObjectCustomizeDiff(_ context.Context, d *schema.Reso
 
 // deleteAllS3Objects deletes key from an S3 bucket.
 // If key is empty then all objects are deleted.
+// If expectedBucketOwner is not empty, S3 rejects requests to a bucket owned by another account.
+// If requestPayer is not empty, the requests to a requester pays bucket are charged to the caller.
 // Set force to true to override any S3 object lock protections on object lock enabled buckets.
 func deleteAllS3Objects(
 	ctx context.Context,
-	conn *s3.S3,
-	bucketName, key string,
+	conn s3iface.S3API,
+	bucketName, key, expectedBucketOwner, requestPayer string,
 	force, ignoreObjectErrors bool,
 ) error {
 	// TODO: Replace to ListObjectVersionsInput when implement.
@@ -418,6 +1837,14 @@ func deleteAllS3Objects(
 		input.Prefix = aws.String(key)
 	}
 
+	if expectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(expectedBucketOwner)
+	}
+
+	if requestPayer != "" {
+		input.RequestPayer = aws.String(requestPayer)
+	}
+
 	var lastErr error
 
 	err := conn.ListObjectsV2PagesWithContext(
@@ -435,7 +1862,7 @@ func deleteAllS3Objects(
 					continue
 				}
 
-				err := deleteS3ObjectVersion(ctx, conn, bucketName, objectKey, "", force)
+				err := deleteS3ObjectVersion(ctx, conn, bucketName, objectKey, "", expectedBucketOwner, requestPayer, force)
 				if err != nil {
 					lastErr = err
 				}
@@ -482,7 +1909,7 @@ func deleteAllS3Objects(
 
 // deleteS3ObjectVersion deletes a specific bucket object version.
 // Set force to true to override any S3 object lock protections.
-func deleteS3ObjectVersion(ctx context.Context, conn *s3.S3, b, k, v string, force bool) error {
+func deleteS3ObjectVersion(ctx context.Context, conn s3iface.S3API, b, k, v, owner, payer string, force bool) error {
 	input := &s3.DeleteObjectInput{
 		Bucket: aws.String(b),
 		Key:    aws.String(k),
@@ -492,6 +1919,14 @@ func deleteS3ObjectVersion(ctx context.Context, conn *s3.S3, b, k, v string, for
 		input.VersionId = aws.String(v)
 	}
 
+	if owner != "" {
+		input.ExpectedBucketOwner = aws.String(owner)
+	}
+
+	if payer != "" {
+		input.RequestPayer = aws.String(payer)
+	}
+
 	if force {
 		input.BypassGovernanceRetention = aws.Bool(true)
 	}
@@ -0,0 +1,278 @@
+package rabata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// defaultS3ObjectFromURLMaxSize is the default maximum size of a fetched source, 5 GiB.
+	defaultS3ObjectFromURLMaxSize = 5 << 30
+	s3ObjectFromURLMaxRedirects   = 10
+)
+
+func resourceRabataS3BucketObjectFromURL() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRabataS3BucketObjectFromURLCreate,
+		ReadContext:   resourceRabataS3BucketObjectFromURLRead,
+		DeleteContext: resourceRabataS3BucketObjectFromURLDelete,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"source_url": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+
+			"headers": {
+				Type:      schema.TypeMap,
+				Optional:  true,
+				ForceNew:  true,
+				Sensitive: true,
+				Elem:      &schema.Schema{Type: schema.TypeString},
+			},
+
+			"max_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      defaultS3ObjectFromURLMaxSize,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"acl": {
+				Type:     schema.TypeString,
+				Default:  s3.ObjectCannedACLPrivate,
+				Optional: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					s3.ObjectCannedACLPrivate,
+					s3.ObjectCannedACLPublicRead,
+					s3.ObjectCannedACLPublicReadWrite,
+					s3.ObjectCannedACLAuthenticatedRead,
+					s3.ObjectCannedACLAwsExecRead,
+					s3.ObjectCannedACLBucketOwnerRead,
+					s3.ObjectCannedACLBucketOwnerFullControl,
+				}, false),
+			},
+
+			"content_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+
+			"content_length": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"version_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceRabataS3BucketObjectFromURLCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket, _ := d.Get("bucket").(string)
+	key, _ := d.Get("key").(string)
+	sourceURL, _ := d.Get("source_url").(string)
+	headers, _ := d.Get("headers").(map[string]any)
+	maxSize := int64(d.Get("max_size").(int)) //nolint:forcetypeassert
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return diag.Errorf("error creating request for source_url: %s", err)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v.(string)) //nolint:forcetypeassert
+	}
+
+	client := &http.Client{CheckRedirect: checkS3ObjectFromURLRedirect}
+
+	// The URL is not logged as it may be presigned.
+	log.Printf("[DEBUG] Fetching source for S3 Bucket (%s) Object (%s)", bucket, key)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.Errorf("error fetching source_url: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return diag.Errorf("error fetching source_url: unexpected HTTP status %s", resp.Status)
+	}
+
+	if resp.ContentLength > maxSize {
+		return diag.Errorf("source_url content (%d bytes) is larger than max_size (%d bytes)", resp.ContentLength, maxSize)
+	}
+
+	contentType, _ := d.Get("content_type").(string)
+	if contentType == "" {
+		contentType = resp.Header.Get("Content-Type")
+	}
+
+	input := &s3manager.UploadInput{
+		ACL:    aws.String(d.Get("acl").(string)), //nolint:forcetypeassert
+		Body:   &s3ObjectSizeLimitReader{r: resp.Body, limit: maxSize},
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	uploader := s3manager.NewUploaderWithClient(awsClient.s3conn, func(u *s3manager.Uploader) {
+		u.PartSize = awsClient.multipartPartSize
+	})
+
+	if _, err := uploader.UploadWithContext(ctx, input); err != nil {
+		return diag.Errorf("Error putting object in S3 bucket (%s): %s", bucket, err)
+	}
+
+	d.SetId(key)
+
+	return resourceRabataS3BucketObjectFromURLRead(ctx, d, meta)
+}
+
+func resourceRabataS3BucketObjectFromURLRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	resp, err := s3conn.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.RequestFailure
+		// If S3 returns a 404 Request Failure, mark the object as destroyed
+		if errors.As(err, &awsErr) && awsErr.StatusCode() == http.StatusNotFound {
+			d.SetId("")
+			log.Printf("[WARN] Error Reading Object (%s), object not found (HTTP status 404)", key)
+
+			return nil
+		}
+
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Reading S3 Bucket Object meta: %s", resp)
+
+	d.Set("content_length", aws.Int64Value(resp.ContentLength)) //nolint:errcheck
+	d.Set("content_type", resp.ContentType)                     //nolint:errcheck
+	d.Set("version_id", resp.VersionId)                         //nolint:errcheck
+	// See https://forums.aws.amazon.com/thread.jspa?threadID=44003
+	d.Set("etag", strings.Trim(aws.StringValue(resp.ETag), `"`)) //nolint:errcheck
+
+	return nil
+}
+
+func resourceRabataS3BucketObjectFromURLDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	bucket, _ := d.Get("bucket").(string)
+	key, _ := d.Get("key").(string)
+
+	var err error
+	if _, ok := d.GetOk("version_id"); ok {
+		err = deleteAllS3Objects(ctx, awsClient.s3conn, bucket, key, "", "", false, false)
+	} else {
+		err = deleteS3ObjectVersion(ctx, awsClient.s3conn, bucket, key, "", "", "", false)
+	}
+
+	if err != nil {
+		return diag.Errorf("error deleting S3 Bucket (%s) Object (%s): %s", bucket, key, err)
+	}
+
+	return nil
+}
+
+// checkS3ObjectFromURLRedirect limits the number of redirects followed
+// and refuses to downgrade from HTTPS to HTTP.
+func checkS3ObjectFromURLRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= s3ObjectFromURLMaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", s3ObjectFromURLMaxRedirects)
+	}
+
+	if via[0].URL.Scheme == "https" && req.URL.Scheme != "https" {
+		return errors.New("refusing to follow redirect from HTTPS to HTTP")
+	}
+
+	return nil
+}
+
+// s3ObjectSizeLimitReader fails the upload once more than limit bytes have been read,
+// for sources that do not report their Content-Length.
+type s3ObjectSizeLimitReader struct {
+	r     io.Reader
+	read  int64
+	limit int64
+}
+
+func (r *s3ObjectSizeLimitReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+
+	if r.read > r.limit {
+		return n, fmt.Errorf("source_url content is larger than max_size (%d bytes)", r.limit)
+	}
+
+	return n, err
+}
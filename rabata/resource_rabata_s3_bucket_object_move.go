@@ -0,0 +1,327 @@
+package rabata
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// s3ObjectOriginalLastModifiedMetadataKey is the user metadata key holding the last modified time
+// of the source object, which a copy does not keep.
+const s3ObjectOriginalLastModifiedMetadataKey = "original-last-modified"
+
+// resourceRabataS3BucketObjectMove renames an object within a bucket.
+// S3 has no rename operation, so the object is copied to the new key and the old key is deleted.
+// Destroying the resource only removes it from the state, the object stays at its new key.
+func resourceRabataS3BucketObjectMove() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRabataS3BucketObjectMoveCreate,
+		ReadContext:   resourceRabataS3BucketObjectMoveRead,
+		DeleteContext: resourceRabataS3BucketObjectMoveDelete,
+
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"source_key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"acl": {
+				Type:     schema.TypeString,
+				Default:  s3.ObjectCannedACLPrivate,
+				Optional: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					s3.ObjectCannedACLPrivate,
+					s3.ObjectCannedACLPublicRead,
+					s3.ObjectCannedACLPublicReadWrite,
+					s3.ObjectCannedACLAuthenticatedRead,
+					s3.ObjectCannedACLAwsExecRead,
+					s3.ObjectCannedACLBucketOwnerRead,
+					s3.ObjectCannedACLBucketOwnerFullControl,
+				}, false),
+			},
+
+			"propagate_metadata": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+				Description: "Read the metadata, content headers and tags of the source object and set them " +
+					"explicitly on the copy, for backends that do not carry all of them over with the COPY directive.",
+			},
+
+			"preserve_last_modified": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+				Description: "Store the last modified time of the source object in the `original-last-modified` " +
+					"user metadata of the copy, keeping the value of a source that already has one. " +
+					"Implies propagate_metadata, as the metadata of the copy is replaced.",
+			},
+
+			"original_last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"version_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceRabataS3BucketObjectMoveCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	awsClient, diags := awsClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := awsClient.checkWritable(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	conn := awsClient.s3conn
+	bucket, _ := d.Get("bucket").(string)
+	sourceKey, _ := d.Get("source_key").(string)
+	key, _ := d.Get("key").(string)
+
+	if sourceKey == key {
+		return diag.Errorf("source_key and key must be different")
+	}
+
+	// A previous apply may have moved the object without recording it in the state.
+	sourceExists, err := s3ObjectExists(ctx, conn, bucket, sourceKey)
+	if err != nil {
+		return diag.Errorf("error reading S3 Bucket (%s) Object (%s): %s", bucket, sourceKey, err)
+	}
+
+	if !sourceExists {
+		keyExists, err := s3ObjectExists(ctx, conn, bucket, key)
+		if err != nil {
+			return diag.Errorf("error reading S3 Bucket (%s) Object (%s): %s", bucket, key, err)
+		}
+
+		if !keyExists {
+			return diag.Errorf("S3 Bucket (%s) Object (%s) not found", bucket, sourceKey)
+		}
+
+		log.Printf("[INFO] S3 Bucket (%s) Object (%s) already moved to %s", bucket, sourceKey, key)
+
+		d.SetId(bucket + "/" + key)
+
+		return resourceRabataS3BucketObjectMoveRead(ctx, d, meta)
+	}
+
+	log.Printf("[DEBUG] Copying S3 Bucket (%s) Object (%s) to %s", bucket, sourceKey, key)
+
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(bucket + "/" + url.PathEscape(sourceKey)),
+		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+		ACL:               aws.String(d.Get("acl").(string)), //nolint:forcetypeassert
+	}
+
+	propagateMetadata, _ := d.Get("propagate_metadata").(bool)
+	preserveLastModified, _ := d.Get("preserve_last_modified").(bool)
+
+	if propagateMetadata || preserveLastModified {
+		head, err := setS3CopyObjectSourceMetadata(ctx, conn, input, bucket, sourceKey)
+		if err != nil {
+			return diag.Errorf("error reading S3 Bucket (%s) Object (%s) metadata: %s", bucket, sourceKey, err)
+		}
+
+		if preserveLastModified {
+			setS3CopyObjectOriginalLastModified(input, head)
+		}
+	}
+
+	_, err = conn.CopyObjectWithContext(ctx, input)
+	if err != nil {
+		return diag.Errorf("error copying S3 Bucket (%s) Object (%s) to %s: %s", bucket, sourceKey, key, err)
+	}
+
+	if err := deleteS3ObjectVersion(ctx, conn, bucket, sourceKey, "", "", "", false); err != nil {
+		// Roll back the copy, so that the object is not left under both keys.
+		if rollbackErr := deleteS3ObjectVersion(ctx, conn, bucket, key, "", "", "", false); rollbackErr != nil {
+			return diag.Errorf("error deleting S3 Bucket (%s) Object (%s): %s; error rolling back copy to %s: %s",
+				bucket, sourceKey, err, key, rollbackErr)
+		}
+
+		return diag.Errorf("error deleting S3 Bucket (%s) Object (%s), copy to %s rolled back: %s",
+			bucket, sourceKey, key, err)
+	}
+
+	d.SetId(bucket + "/" + key)
+
+	return resourceRabataS3BucketObjectMoveRead(ctx, d, meta)
+}
+
+func resourceRabataS3BucketObjectMoveRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	s3conn := meta.(*AWSClient).s3conn //nolint:forcetypeassert
+
+	bucket := d.Get("bucket").(string) //nolint:forcetypeassert
+	key := d.Get("key").(string)       //nolint:forcetypeassert
+
+	resp, err := s3conn.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.RequestFailure
+		// If S3 returns a 404 Request Failure, mark the object as destroyed
+		if errors.As(err, &awsErr) && awsErr.StatusCode() == http.StatusNotFound {
+			d.SetId("")
+			log.Printf("[WARN] Error Reading Object (%s), object not found (HTTP status 404)", key)
+
+			return nil
+		}
+
+		return diag.FromErr(err)
+	}
+
+	// See https://forums.aws.amazon.com/thread.jspa?threadID=44003
+	d.Set("etag", strings.Trim(aws.StringValue(resp.ETag), `"`)) //nolint:errcheck
+	d.Set("version_id", resp.VersionId)                          //nolint:errcheck
+
+	d.Set("original_last_modified", s3ObjectOriginalLastModified(resp.Metadata)) //nolint:errcheck
+
+	return nil
+}
+
+func resourceRabataS3BucketObjectMoveDelete(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	log.Printf("[INFO] Removing S3 Bucket Object move (%s) from state, the object is kept", d.Id())
+
+	return nil
+}
+
+// setS3CopyObjectSourceMetadata replaces the COPY directives of input with the metadata,
+// content headers and tags read from the source object, and returns the source object head.
+func setS3CopyObjectSourceMetadata(
+	ctx context.Context,
+	conn s3iface.S3API,
+	input *s3.CopyObjectInput,
+	bucket, key string,
+) (*s3.HeadObjectOutput, error) {
+	head, err := conn.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tagging, err := conn.GetObjectTaggingWithContext(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The REPLACE directive drops every header that is not sent again.
+	input.MetadataDirective = aws.String(s3.MetadataDirectiveReplace)
+	input.Metadata = head.Metadata
+	input.CacheControl = head.CacheControl
+	input.ContentDisposition = head.ContentDisposition
+	input.ContentEncoding = head.ContentEncoding
+	input.ContentLanguage = head.ContentLanguage
+	input.ContentType = head.ContentType
+
+	// An unparsable Expires header is treated as already expired by HTTP caches, so it is dropped.
+	if expires, err := http.ParseTime(aws.StringValue(head.Expires)); err == nil {
+		input.Expires = aws.Time(expires)
+	}
+
+	tags := url.Values{}
+	for _, tag := range tagging.TagSet {
+		tags.Set(aws.StringValue(tag.Key), aws.StringValue(tag.Value))
+	}
+
+	input.TaggingDirective = aws.String(s3.TaggingDirectiveReplace)
+	input.Tagging = aws.String(tags.Encode())
+
+	log.Printf("[DEBUG] Propagating %d metadata keys and %d tags of S3 Bucket (%s) Object (%s)",
+		len(head.Metadata), len(tagging.TagSet), bucket, key)
+
+	return head, nil
+}
+
+// setS3CopyObjectOriginalLastModified adds the last modified time of the source object to the metadata of input,
+// unless the source object is itself a copy carrying the original time.
+func setS3CopyObjectOriginalLastModified(input *s3.CopyObjectInput, head *s3.HeadObjectOutput) {
+	if s3ObjectOriginalLastModified(input.Metadata) != "" || head.LastModified == nil {
+		return
+	}
+
+	if input.Metadata == nil {
+		input.Metadata = make(map[string]*string)
+	}
+
+	input.Metadata[s3ObjectOriginalLastModifiedMetadataKey] = aws.String(head.LastModified.UTC().Format(time.RFC3339))
+}
+
+// s3ObjectOriginalLastModified returns the original-last-modified user metadata,
+// whose key case depends on the backend.
+func s3ObjectOriginalLastModified(metadata map[string]*string) string {
+	for k, v := range metadata {
+		if strings.EqualFold(k, s3ObjectOriginalLastModifiedMetadataKey) {
+			return aws.StringValue(v)
+		}
+	}
+
+	return ""
+}
+
+// s3ObjectExists returns true if HeadObject finds the key.
+func s3ObjectExists(ctx context.Context, conn s3iface.S3API, bucket, key string) (bool, error) {
+	_, err := conn.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if isAWSErrRequestFailureStatusCode(err, http.StatusNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package rabata
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestResourceRabataS3BucketObjectMovePropagateMetadata(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		propagateMetadata  bool
+		dropMetadataOnCopy bool
+		expectPropagated   bool
+	}{
+		{name: "copy directive", expectPropagated: true},
+		{name: "copy directive dropping metadata", dropMetadataOnCopy: true},
+		{name: "propagated", propagateMetadata: true, dropMetadataOnCopy: true, expectPropagated: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := newFakeS3()
+			conn.dropMetadataOnCopy = tc.dropMetadataOnCopy
+
+			_, err := conn.PutObjectWithContext(t.Context(), &s3.PutObjectInput{
+				Bucket:      aws.String("tf-test"),
+				Key:         aws.String("old name.txt"),
+				ContentType: aws.String("text/plain"),
+				Metadata:    aws.StringMap(map[string]string{"owner": "tf", "build": "42"}),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			source := conn.objects["old name.txt"]
+			source.tags = map[string]string{"team": "storage", "env": "test & dev"}
+			sourceMetadata, sourceTags := maps.Clone(source.metadata), maps.Clone(source.tags)
+
+			applyS3Resource(t, resourceRabataS3BucketObjectMove(), &AWSClient{s3conn: conn}, nil, map[string]any{
+				"bucket":             "tf-test",
+				"source_key":         "old name.txt",
+				"key":                "new name.txt",
+				"propagate_metadata": tc.propagateMetadata,
+			})
+
+			if _, ok := conn.objects["old name.txt"]; ok {
+				t.Error("expected the source object to be deleted")
+			}
+
+			destination, ok := conn.objects["new name.txt"]
+			if !ok {
+				t.Fatal("expected the destination object to exist")
+			}
+
+			propagated := maps.Equal(destination.metadata, sourceMetadata) && maps.Equal(destination.tags, sourceTags)
+			if propagated != tc.expectPropagated {
+				t.Errorf("expected metadata and tags propagated %t, got metadata %v and tags %v",
+					tc.expectPropagated, destination.metadata, destination.tags)
+			}
+
+			if tc.expectPropagated && destination.contentType != "text/plain" {
+				t.Errorf("expected content type text/plain, got %q", destination.contentType)
+			}
+		})
+	}
+}
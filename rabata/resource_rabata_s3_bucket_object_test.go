@@ -0,0 +1,671 @@
+package rabata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestVerifyS3ObjectChecksumSHA256(t *testing.T) {
+	t.Parallel()
+
+	const bodySHA256 = "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg="
+
+	testCases := map[string]struct {
+		checksum  *string
+		expectErr bool
+	}{
+		"match": {
+			checksum: aws.String(bodySHA256),
+		},
+		"mismatch": {
+			checksum:  aws.String("47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="),
+			expectErr: true,
+		},
+		"none returned": {},
+		"empty returned": {
+			checksum: aws.String(""),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyS3ObjectChecksumSHA256(bodySHA256, &s3.PutObjectOutput{ChecksumSHA256: tc.checksum})
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error, got none")
+			}
+
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeS3ETag(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		etag     any
+		expected string
+	}{
+		"quoted": {
+			etag:     `"d41d8cd98f00b204e9800998ecf8427e"`,
+			expected: "d41d8cd98f00b204e9800998ecf8427e",
+		},
+		"unquoted": {
+			etag:     "d41d8cd98f00b204e9800998ecf8427e",
+			expected: "d41d8cd98f00b204e9800998ecf8427e",
+		},
+		"multipart": {
+			etag:     `"d41d8cd98f00b204e9800998ecf8427e-2"`,
+			expected: "d41d8cd98f00b204e9800998ecf8427e-2",
+		},
+		"empty": {
+			etag: "",
+		},
+		"not a string": {
+			etag: nil,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := normalizeS3ETag(tc.etag); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSuppressUnsetStringDiff(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		oldValue string
+		newValue string
+		expected bool
+	}{
+		"removed": {
+			oldValue: "en-US",
+		},
+		"unset and empty": {
+			expected: true,
+		},
+		"set": {
+			oldValue: "en-US",
+			newValue: "de-DE",
+		},
+		"added": {
+			newValue: "en-US",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := suppressUnsetStringDiff("content_language", tc.oldValue, tc.newValue, nil); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestS3ObjectContentType(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		contentType       string
+		hasContent        bool
+		sourceContentType string
+		expected          string
+	}{
+		"configured": {
+			contentType: "application/json",
+			hasContent:  true,
+			expected:    "application/json",
+		},
+		"inline content": {
+			hasContent: true,
+			expected:   defaultS3ObjectContentType,
+		},
+		"source": {
+			sourceContentType: "image/png",
+			expected:          "image/png",
+		},
+		"configured over source": {
+			contentType:       "application/octet-stream",
+			sourceContentType: "image/png",
+			expected:          "application/octet-stream",
+		},
+		"unknown": {},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := s3ObjectContentType(tc.contentType, tc.hasContent, tc.sourceContentType); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetectS3ObjectContentType(t *testing.T) {
+	t.Parallel()
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+	largeJSON := `{"items": [` + strings.Repeat(`"item", `, 100) + `"item"]}`
+
+	testCases := []struct {
+		name     string
+		fileName string
+		content  []byte
+		expected string
+	}{
+		{name: "extension", fileName: "index.html", content: png, expected: "text/html; charset=utf-8"},
+		{name: "png without extension", fileName: "image", content: png, expected: "image/png"},
+		{name: "json without extension", fileName: "config", content: []byte(`{"a": 1}`), expected: "application/json"},
+		{name: "json array", fileName: "list", content: []byte(" [1, 2, 3]\n"), expected: "application/json"},
+		{name: "json larger than the sniffed bytes", fileName: "large", content: []byte(largeJSON),
+			expected: "application/json"},
+		{name: "not json", fileName: "braces", content: []byte("{not json}"), expected: "text/plain; charset=utf-8"},
+		{name: "json stream", fileName: "stream", content: []byte(`{"a": 1}{"b": 2}`),
+			expected: "text/plain; charset=utf-8"},
+		{name: "text", fileName: "README", content: []byte("hello"), expected: "text/plain; charset=utf-8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			if err := os.WriteFile(path, tc.content, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			got, err := detectS3ObjectContentType(file, path)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+
+			// The file is uploaded from the start after the detection.
+			if body, err := io.ReadAll(file); err != nil || !bytes.Equal(body, tc.content) {
+				t.Errorf("expected the file to be rewound, read %q, %v", body, err)
+			}
+		})
+	}
+}
+
+func TestVerifyS3ObjectETag(t *testing.T) {
+	t.Parallel()
+
+	const bodyMD5 = "5d41402abc4b2a76b9719d911017c592"
+
+	testCases := map[string]struct {
+		out       *s3.PutObjectOutput
+		expectErr bool
+	}{
+		"match": {
+			out: &s3.PutObjectOutput{ETag: aws.String(`"5d41402abc4b2a76b9719d911017c592"`)},
+		},
+		"match upper case": {
+			out: &s3.PutObjectOutput{ETag: aws.String(`"5D41402ABC4B2A76B9719D911017C592"`)},
+		},
+		"mismatch": {
+			out:       &s3.PutObjectOutput{ETag: aws.String(`"d41d8cd98f00b204e9800998ecf8427e"`)},
+			expectErr: true,
+		},
+		"multipart": {
+			out: &s3.PutObjectOutput{ETag: aws.String(`"d41d8cd98f00b204e9800998ecf8427e-2"`)},
+		},
+		"sse-kms": {
+			out: &s3.PutObjectOutput{
+				ETag:                 aws.String(`"d41d8cd98f00b204e9800998ecf8427e"`),
+				ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
+			},
+		},
+		"sse-c": {
+			out: &s3.PutObjectOutput{
+				ETag:                 aws.String(`"d41d8cd98f00b204e9800998ecf8427e"`),
+				SSECustomerAlgorithm: aws.String("AES256"),
+			},
+		},
+		"none returned": {
+			out: &s3.PutObjectOutput{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyS3ObjectETag(bodyMD5, tc.out)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error, got none")
+			}
+
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateS3ObjectExpireAfter(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		value     string
+		expectErr bool
+	}{
+		"one day":       {value: "24h"},
+		"thirty days":   {value: "720h"},
+		"less than day": {value: "12h", expectErr: true},
+		"partial day":   {value: "36h", expectErr: true},
+		"not duration":  {value: "30d", expectErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, errs := validateS3ObjectExpireAfter(tc.value, "expire_after")
+			if tc.expectErr != (len(errs) > 0) {
+				t.Errorf("expected error %t, got %v", tc.expectErr, errs)
+			}
+		})
+	}
+}
+
+func TestResourceRabataS3BucketObjectExpireAfter(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeS3()
+	meta := &AWSClient{s3conn: conn}
+	r := resourceRabataS3BucketObject()
+	config := map[string]any{
+		"bucket":       "tf-test",
+		"key":          "k",
+		"content":      "body",
+		"expire_after": "720h",
+	}
+
+	state := applyS3Resource(t, r, meta, nil, config)
+
+	if got := conn.objects["k"].tags[s3ObjectExpireAfterTagKey]; got != "30d" {
+		t.Errorf("expected tag %s=30d, got %q", s3ObjectExpireAfterTagKey, got)
+	}
+
+	if got, expected := state.Attributes["expires_at"], "2024-01-31T00:00:00Z"; got != expected {
+		t.Errorf("expected expires_at %s, got %s", expected, got)
+	}
+
+	// Changing the duration keeps the other tags of the object and the tag value stays fixed per duration.
+	conn.objects["k"].tags["team"] = "storage"
+	config["expire_after"] = "48h"
+	state = applyS3Resource(t, r, meta, state, config)
+
+	expectedTags := map[string]string{s3ObjectExpireAfterTagKey: "2d", "team": "storage"}
+	if got := conn.objects["k"].tags; !maps.Equal(got, expectedTags) {
+		t.Errorf("expected tags %v, got %v", expectedTags, got)
+	}
+
+	delete(config, "expire_after")
+	state = applyS3Resource(t, r, meta, state, config)
+
+	if got := conn.objects["k"].tags; !maps.Equal(got, map[string]string{"team": "storage"}) {
+		t.Errorf("expected the %s tag to be removed, got %v", s3ObjectExpireAfterTagKey, got)
+	}
+
+	if got := state.Attributes["expires_at"]; got != "" {
+		t.Errorf("expected no expires_at, got %s", got)
+	}
+}
+
+func TestResourceRabataS3BucketObjectDeleteInvalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		meta  any
+		state map[string]any
+	}{
+		{name: "no provider configuration", meta: nil, state: map[string]any{"bucket": "tf-test", "key": "k"}},
+		{name: "unexpected provider configuration", meta: "client", state: map[string]any{"bucket": "tf-test", "key": "k"}},
+		{name: "no bucket", meta: &AWSClient{}, state: map[string]any{"key": "k"}},
+		{name: "no key", meta: &AWSClient{}, state: map[string]any{"bucket": "tf-test"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := resourceRabataS3BucketObject().TestResourceData()
+			d.SetId("k")
+
+			for k, v := range tc.state {
+				if err := d.Set(k, v); err != nil {
+					t.Fatalf("error setting %s: %s", k, err)
+				}
+			}
+
+			// The client has no S3 connection, the delete must fail before sending any request.
+			if diags := resourceRabataS3BucketObjectDelete(context.Background(), d, tc.meta); !diags.HasError() {
+				t.Error("expected an error, got none")
+			}
+		})
+	}
+}
+
+func TestResourceRabataS3BucketObjectContentLanguageRemovedDiff(t *testing.T) {
+	t.Parallel()
+
+	state := &terraform.InstanceState{
+		ID: "k",
+		Attributes: map[string]string{
+			"bucket":           "tf-test",
+			"key":              "k",
+			"content_language": "en-US",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]any{
+		"bucket": "tf-test",
+		"key":    "k",
+	})
+
+	diff, err := resourceRabataS3BucketObject().Diff(context.Background(), state, config, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if diff == nil || diff.Attributes["content_language"] == nil {
+		t.Fatal("expected a content_language diff, got none")
+	}
+
+	if got := diff.Attributes["content_language"].New; got != "" {
+		t.Errorf("expected content_language to be cleared, got %q", got)
+	}
+}
+
+func TestResourceRabataS3BucketObjectMetadataKeyRemoved(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeS3()
+	conn.systemMetadata = map[string]string{"X-Rabata-Node": "node-1"}
+	meta := &AWSClient{s3conn: conn}
+	r := resourceRabataS3BucketObject()
+
+	state := applyS3Resource(t, r, meta, nil, map[string]any{
+		"bucket":   "tf-test",
+		"key":      "k",
+		"content":  "body",
+		"metadata": map[string]any{"kept": "1", "removed": "2", "x-amz-meta-legacy": "3"},
+	})
+	if got := state.Attributes["metadata.%"]; got != "3" {
+		t.Fatalf("expected 3 metadata keys without the system metadata, got %s: %v", got, state.Attributes)
+	}
+
+	state = applyS3Resource(t, r, meta, state, map[string]any{
+		"bucket":   "tf-test",
+		"key":      "k",
+		"content":  "body",
+		"metadata": map[string]any{"kept": "1", "x-amz-meta-legacy": "3"},
+	})
+
+	if _, ok := conn.objects["k"].metadata["removed"]; ok {
+		t.Error("expected the removed metadata key to be gone from the object")
+	}
+
+	if _, ok := state.Attributes["metadata.removed"]; ok {
+		t.Error("expected the removed metadata key to be gone from the state")
+	}
+
+	if got := state.Attributes["metadata.x-amz-meta-legacy"]; got != "3" {
+		t.Errorf("expected the configured x-amz- key to be kept, got %q", got)
+	}
+
+	if got := state.Attributes["metadata.%"]; got != "2" {
+		t.Errorf("expected 2 metadata keys, got %s", got)
+	}
+}
+
+func TestResourceRabataS3BucketObjectLegalHoldUpdate(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeS3()
+	meta := &AWSClient{s3conn: conn}
+	r := resourceRabataS3BucketObject()
+	config := map[string]any{
+		"bucket":  "tf-test",
+		"key":     "k",
+		"content": "body",
+	}
+
+	state := applyS3Resource(t, r, meta, nil, config)
+	versionID, etag := state.Attributes["version_id"], state.Attributes["etag"]
+
+	for _, status := range []string{s3.ObjectLockLegalHoldStatusOn, s3.ObjectLockLegalHoldStatusOff} {
+		conn.Calls()
+
+		config["object_lock_legal_hold_status"] = status
+		state = applyS3Resource(t, r, meta, state, config)
+
+		if calls := conn.Calls(); slices.Contains(calls, "PutObject") || !slices.Contains(calls, "PutObjectLegalHold") {
+			t.Errorf("expected only PutObjectLegalHold to change the object, got %v", calls)
+		}
+
+		if got := state.Attributes["object_lock_legal_hold_status"]; got != status {
+			t.Errorf("expected legal hold %s, got %s", status, got)
+		}
+
+		if state.Attributes["version_id"] != versionID || state.Attributes["etag"] != etag {
+			t.Errorf("expected version %s and etag %s to be unchanged, got %s and %s",
+				versionID, etag, state.Attributes["version_id"], state.Attributes["etag"])
+		}
+	}
+}
+
+func TestResourceRabataS3BucketObjectACLOnlyUpdate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		update map[string]any
+	}{
+		{name: "canned acl", update: map[string]any{"acl": s3.ObjectCannedACLPublicRead}},
+		{name: "grant header", update: map[string]any{"grant_read": `id="tf-test-reader"`}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := newFakeS3()
+			meta := &AWSClient{s3conn: conn}
+			r := resourceRabataS3BucketObject()
+			config := map[string]any{
+				"bucket":       "tf-test",
+				"key":          "k",
+				"content":      "body",
+				"content_type": "application/json",
+				"metadata":     map[string]any{"owner": "tf"},
+			}
+
+			state := applyS3Resource(t, r, meta, nil, config)
+			conn.Calls()
+
+			maps.Copy(config, tc.update)
+			state = applyS3Resource(t, r, meta, state, config)
+
+			calls := conn.Calls()
+			if !slices.Contains(calls, "PutObjectAcl") {
+				t.Errorf("expected PutObjectAcl, got %v", calls)
+			}
+
+			for _, call := range calls {
+				if call != "PutObjectAcl" && call != "HeadObject" {
+					t.Errorf("expected only PutObjectAcl to change the object, got %s", call)
+				}
+			}
+
+			object := conn.objects["k"]
+			if !maps.Equal(object.metadata, map[string]string{"owner": "tf"}) || object.contentType != "application/json" {
+				t.Errorf("expected metadata and content type to be unchanged, got %v and %s",
+					object.metadata, object.contentType)
+			}
+
+			if got := state.Attributes["metadata.owner"]; got != "tf" {
+				t.Errorf("expected metadata in the state to be unchanged, got %q", got)
+			}
+		})
+	}
+}
+
+func TestResourceRabataS3BucketObjectGzip(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeS3()
+	meta := &AWSClient{s3conn: conn}
+	source := filepath.Join(t.TempDir(), "index.html")
+	content := strings.Repeat("<p>hello</p>\n", 100)
+
+	if err := os.WriteFile(source, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	state := applyS3Resource(t, resourceRabataS3BucketObject(), meta, nil, map[string]any{
+		"bucket": "tf-test",
+		"key":    "index.html",
+		"source": source,
+		"gzip":   true,
+	})
+
+	object := conn.objects["index.html"]
+	if object.contentEncoding != s3ObjectContentEncodingGzip {
+		t.Errorf("expected Content-Encoding gzip, got %q", object.contentEncoding)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(object.body))
+	if err != nil {
+		t.Fatalf("expected a gzip body: %s", err)
+	}
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("error decompressing the body: %s", err)
+	}
+
+	if string(body) != content {
+		t.Errorf("expected the body to decompress to the source, got %q", body)
+	}
+
+	if got := state.Attributes["content_encoding"]; got != "" {
+		t.Errorf("expected the gzip encoding not to show up in content_encoding, got %q", got)
+	}
+}
+
+// headObjectErrS3API fails every HeadObject request with err.
+type headObjectErrS3API struct {
+	s3iface.S3API
+
+	err error
+}
+
+func (c *headObjectErrS3API) HeadObjectWithContext(
+	_ aws.Context,
+	_ *s3.HeadObjectInput,
+	_ ...request.Option,
+) (*s3.HeadObjectOutput, error) {
+	return nil, c.err
+}
+
+func TestResourceRabataS3BucketObjectReadHeadObjectError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		err             error
+		expectErr       bool
+		expectedSummary string
+		expectedID      string
+	}{
+		{
+			name: "forbidden",
+			err: awserr.NewRequestFailure(awserr.New("Forbidden", "Forbidden", nil),
+				http.StatusForbidden, ""),
+			expectErr:       true,
+			expectedSummary: "Access denied reading S3 Bucket (tf-test) Object (k)",
+			expectedID:      "k",
+		},
+		{
+			name: "not found",
+			err: awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil),
+				http.StatusNotFound, ""),
+		},
+		{
+			name: "other error",
+			err: awserr.NewRequestFailure(awserr.New("InternalError", "Internal Error", nil),
+				http.StatusInternalServerError, ""),
+			expectErr:  true,
+			expectedID: "k",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := resourceRabataS3BucketObject().TestResourceData()
+			d.SetId("k")
+			d.Set("bucket", "tf-test") //nolint:errcheck
+			d.Set("key", "k")          //nolint:errcheck
+
+			meta := &AWSClient{s3conn: &headObjectErrS3API{err: tc.err}}
+
+			diags := resourceRabataS3BucketObjectRead(context.Background(), d, meta)
+			if diags.HasError() != tc.expectErr {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, diags)
+			}
+
+			if tc.expectedSummary != "" && diags[0].Summary != tc.expectedSummary {
+				t.Errorf("expected summary %q, got %q", tc.expectedSummary, diags[0].Summary)
+			}
+
+			if d.Id() != tc.expectedID {
+				t.Errorf("expected ID %q, got %q", tc.expectedID, d.Id())
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package rabata
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+func TestShouldUpdateS3BucketGrants(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		grantChanged    bool
+		aclModeChanged  bool
+		hasGrants       bool
+		hasGrantHeaders bool
+		expected        bool
+	}{
+		"no change": {
+			hasGrants: true,
+		},
+		"grant changed": {
+			grantChanged: true,
+			hasGrants:    true,
+			expected:     true,
+		},
+		"grant removed falls back to the canned ACL": {
+			grantChanged: true,
+			expected:     true,
+		},
+		"switch to exclusive with grants": {
+			aclModeChanged: true,
+			hasGrants:      true,
+			expected:       true,
+		},
+		"switch to exclusive with the canned ACL": {
+			aclModeChanged: true,
+			expected:       true,
+		},
+		"switch to exclusive with grant headers": {
+			grantChanged:    true,
+			aclModeChanged:  true,
+			hasGrantHeaders: true,
+		},
+		"grant removed for grant headers": {
+			grantChanged:    true,
+			hasGrantHeaders: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := shouldUpdateS3BucketGrants(tc.grantChanged, tc.aclModeChanged, tc.hasGrants, tc.hasGrantHeaders)
+			if got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+// headBucketS3API answers HeadBucket with a fixed error.
+type headBucketS3API struct {
+	s3iface.S3API
+
+	err error
+}
+
+func (c *headBucketS3API) HeadBucketWithContext(
+	_ aws.Context,
+	_ *s3.HeadBucketInput,
+	_ ...request.Option,
+) (*s3.HeadBucketOutput, error) {
+	return &s3.HeadBucketOutput{}, c.err
+}
+
+func TestCheckS3BucketNameAvailable(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err             error
+		expectedMessage string
+	}{
+		"not found": {
+			err: awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), http.StatusNotFound, ""),
+		},
+		"owned by you": {
+			expectedMessage: "owned by you",
+		},
+		"owned by another account": {
+			err:             awserr.NewRequestFailure(awserr.New("Forbidden", "Forbidden", nil), http.StatusForbidden, ""),
+			expectedMessage: "owned by another account",
+		},
+		"in another region": {
+			err: awserr.NewRequestFailure(
+				awserr.New("MovedPermanently", "Moved Permanently", nil), http.StatusMovedPermanently, ""),
+			expectedMessage: "in another region",
+		},
+		"other error": {
+			err: awserr.NewRequestFailure(
+				awserr.New("InternalError", "Internal Error", nil), http.StatusInternalServerError, ""),
+			expectedMessage: "error checking whether",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkS3BucketNameAvailable(context.Background(), &headBucketS3API{err: tc.err}, "tf-test-bucket")
+			if tc.expectedMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.expectedMessage) {
+				t.Errorf("expected an error containing %q, got %v", tc.expectedMessage, err)
+			}
+		})
+	}
+}
+
+// createBucketS3API fails the first CreateBucket requests with createErrs
+// and answers HeadBucket like headBucketS3API.
+type createBucketS3API struct {
+	headBucketS3API
+
+	createErrs  []error
+	createCalls int
+}
+
+func (c *createBucketS3API) CreateBucketWithContext(
+	_ aws.Context,
+	_ *s3.CreateBucketInput,
+	_ ...request.Option,
+) (*s3.CreateBucketOutput, error) {
+	c.createCalls++
+	if c.createCalls <= len(c.createErrs) {
+		return nil, c.createErrs[c.createCalls-1]
+	}
+
+	return &s3.CreateBucketOutput{}, nil
+}
+
+func TestCreateS3Bucket(t *testing.T) {
+	t.Parallel()
+
+	alreadyExists := awserr.NewRequestFailure(
+		awserr.New(s3.ErrCodeBucketAlreadyExists, "The requested bucket name is not available.", nil),
+		http.StatusConflict, "")
+	notFound := awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), http.StatusNotFound, "")
+	forbidden := awserr.NewRequestFailure(awserr.New("Forbidden", "Forbidden", nil), http.StatusForbidden, "")
+
+	testCases := []struct {
+		name            string
+		createErrs      []error
+		headErr         error
+		expectedMessage string
+		expectedCalls   int
+	}{
+		{name: "created", expectedCalls: 1},
+		{
+			name:          "transiently existing then created",
+			createErrs:    []error{alreadyExists},
+			headErr:       notFound,
+			expectedCalls: 2,
+		},
+		{
+			name:            "owned by another account",
+			createErrs:      []error{alreadyExists},
+			headErr:         forbidden,
+			expectedMessage: "owned by another account",
+			expectedCalls:   1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := &createBucketS3API{headBucketS3API: headBucketS3API{err: tc.headErr}, createErrs: tc.createErrs}
+			input := &s3.CreateBucketInput{Bucket: aws.String("tf-test-bucket")}
+
+			err := createS3Bucket(context.Background(), conn, input, time.Minute)
+			if tc.expectedMessage == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if tc.expectedMessage != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedMessage)) {
+				t.Errorf("expected an error containing %q, got %v", tc.expectedMessage, err)
+			}
+
+			if conn.createCalls != tc.expectedCalls {
+				t.Errorf("expected %d CreateBucket calls, got %d", tc.expectedCalls, conn.createCalls)
+			}
+		})
+	}
+}
+
+func TestValidateS3BucketName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		bucket    string
+		expectErr bool
+	}{
+		{name: "valid", bucket: "tf-test-bucket"},
+		{name: "dotted", bucket: "tf.test.bucket"},
+		{name: "too short", bucket: "tf", expectErr: true},
+		{name: "too long", bucket: strings.Repeat("a", 64), expectErr: true},
+		{name: "uppercase", bucket: "TF-Test-Bucket", expectErr: true},
+		{name: "underscore", bucket: "tf_test_bucket", expectErr: true},
+		{name: "starts with a hyphen", bucket: "-tf-test-bucket", expectErr: true},
+		{name: "ends with a period", bucket: "tf-test-bucket.", expectErr: true},
+		{name: "consecutive periods", bucket: "tf..test", expectErr: true},
+		{name: "ip address", bucket: "192.168.1.1", expectErr: true},
+	}
+
+	for _, region := range []string{"eu-west-1", "us-east-1"} {
+		for _, tc := range testCases {
+			t.Run(region+"/"+tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := validateS3BucketName(tc.bucket, region)
+				if tc.expectErr && err == nil {
+					t.Fatalf("expected an error for %q, got none", tc.bucket)
+				}
+
+				if !tc.expectErr && err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			})
+		}
+	}
+}
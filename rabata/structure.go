@@ -1,6 +1,9 @@
 package rabata
 
-import "github.com/aws/aws-sdk-go/aws"
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
 
 func pointersMapToStringList(pointers map[string]*string) map[string]any {
 	list := make(map[string]any, len(pointers))
@@ -19,3 +22,30 @@ func stringMapToPointers(m map[string]any) map[string]*string {
 
 	return list
 }
+
+func expandStringSet(set *schema.Set) []string {
+	list := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		list = append(list, v.(string)) //nolint:forcetypeassert
+	}
+
+	return list
+}
+
+func expandStringList(l []any) []string {
+	list := make([]string, 0, len(l))
+	for _, v := range l {
+		list = append(list, v.(string)) //nolint:forcetypeassert
+	}
+
+	return list
+}
+
+func expandStringMap(m map[string]any) map[string]string {
+	list := make(map[string]string, len(m))
+	for i, v := range m {
+		list[i] = v.(string) //nolint:forcetypeassert
+	}
+
+	return list
+}
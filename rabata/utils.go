@@ -2,8 +2,12 @@ package rabata
 
 import (
 	"errors"
+	"io"
+	"log"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func isResourceTimeoutError(err error) bool {
@@ -13,3 +17,103 @@ func isResourceTimeoutError(err error) bool {
 
 	return ok && timeoutErr.LastError == nil
 }
+
+// uploadProgressLogInterval is the number of bytes between two upload progress log lines.
+const uploadProgressLogInterval = 64 << 20
+
+// progressReader wraps an upload body and logs the number of bytes read so far.
+// The SDK seeks back to the start of the body after signing it, which restarts the count.
+type progressReader struct {
+	io.ReadSeeker
+
+	name    string
+	size    int64
+	read    int64
+	nextLog int64
+}
+
+func newProgressReader(r io.ReadSeeker, name string, size int64) *progressReader {
+	return &progressReader{
+		ReadSeeker: r,
+		name:       name,
+		size:       size,
+		nextLog:    uploadProgressLogInterval,
+	}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadSeeker.Read(p)
+	r.read += int64(n)
+
+	if r.read >= r.nextLog || (errors.Is(err, io.EOF) && r.read > 0) {
+		if r.size > 0 {
+			log.Printf("[INFO] Uploading %s: %d of %d bytes (%d%%)", r.name, r.read, r.size, r.read*100/r.size) //nolint:mnd
+		} else {
+			log.Printf("[INFO] Uploading %s: %d bytes", r.name, r.read)
+		}
+
+		for r.nextLog <= r.read {
+			r.nextLog += uploadProgressLogInterval
+		}
+	}
+
+	return n, err
+}
+
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.ReadSeeker.Seek(offset, whence)
+	if err == nil {
+		r.read = pos
+		r.nextLog = (pos/uploadProgressLogInterval + 1) * uploadProgressLogInterval
+	}
+
+	return pos, err
+}
+
+// awsClientFromMeta returns the configured provider client,
+// or an error diagnostic instead of panicking if meta is of an unexpected type.
+func awsClientFromMeta(meta any) (*AWSClient, diag.Diagnostics) {
+	client, ok := meta.(*AWSClient)
+	if !ok || client == nil {
+		return nil, diag.Errorf("unexpected provider configuration type: %T", meta)
+	}
+
+	return client, nil
+}
+
+// resourceDataString returns the string value of k,
+// or an error diagnostic instead of a zero value if k does not hold a string.
+func resourceDataString(d *schema.ResourceData, k string) (string, diag.Diagnostics) {
+	v, ok := d.Get(k).(string)
+	if !ok {
+		return "", diag.Errorf("unexpected type of %s: %T", k, d.Get(k))
+	}
+
+	return v, nil
+}
+
+// requiredResourceDataString is like resourceDataString, but also returns an error diagnostic if k is empty,
+// so that requests are not sent for an empty bucket name or key.
+func requiredResourceDataString(d *schema.ResourceData, k string) (string, diag.Diagnostics) {
+	v, diags := resourceDataString(d, k)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	if v == "" {
+		return "", diag.Errorf("%s is not set in the resource state", k)
+	}
+
+	return v, nil
+}
+
+// resourceDataBool returns the bool value of k,
+// or an error diagnostic instead of a zero value if k does not hold a bool.
+func resourceDataBool(d *schema.ResourceData, k string) (bool, diag.Diagnostics) {
+	v, ok := d.Get(k).(bool)
+	if !ok {
+		return false, diag.Errorf("unexpected type of %s: %T", k, d.Get(k))
+	}
+
+	return v, nil
+}